@@ -25,10 +25,26 @@ func main() {
 	}
 	defer configs.DisconnectFromMongo()
 
-	if err := configs.RequestIgdbToken(); err != nil {
+	upstreamUrls := configs.LoadUpstreamUrls()
+
+	igdbTokens := configs.NewIgdbTokenManager(upstreamUrls.TwitchOauth, os.Getenv("IGDB_ID"), os.Getenv("IGDB_SECRET"))
+	if err := igdbTokens.RequestToken(); err != nil {
 		log.Fatal(err)
 	}
 
+	handlers.SetUpstreams(handlers.NewUpstreams(upstreamUrls, igdbTokens))
+
+	runConfig, err := configs.LoadRunConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	handlers.SetRunConfig(runConfig)
+
+	// Conversion is a nicety, the bot starts without it and the next run tries again
+	if err := handlers.RefreshExchangeRates(); err != nil {
+		log.Printf("starting without exchange rates: %v", err)
+	}
+
 	// Run bot
 	bot, err := telego.NewBot(os.Getenv("BOT_TOKEN"), telego.WithDefaultDebugLogger())
 	if err != nil {
@@ -48,7 +64,26 @@ func main() {
 
 	botHandler.Handle(handlers.StartHandler, telegohandler.CommandEqual("start"))
 	botHandler.Handle(handlers.ProfileHandler, telegohandler.CommandEqual("profile"))
+	botHandler.Handle(handlers.SteamHandler, telegohandler.CommandEqual("steam"))
 	botHandler.Handle(handlers.CountryHandler, telegohandler.CommandEqual("country"))
+	botHandler.Handle(handlers.CurrencyHandler, telegohandler.CommandEqual("currency"))
+	botHandler.Handle(handlers.LanguageHandler, telegohandler.CommandEqual("language"))
+	botHandler.Handle(handlers.AddHandler, telegohandler.CommandEqual("add"))
+	botHandler.Handle(handlers.RemoveHandler, telegohandler.CommandEqual("remove"))
+	botHandler.Handle(handlers.WatchCallbackHandler, telegohandler.CallbackDataPrefix(handlers.WatchCallbackPrefix))
+	botHandler.Handle(handlers.UnwatchCallbackHandler, telegohandler.CallbackDataPrefix(handlers.UnwatchCallbackPrefix))
+	botHandler.Handle(handlers.MatchCallbackHandler, telegohandler.CallbackDataPrefix(handlers.MatchCallbackPrefix))
+	botHandler.Handle(handlers.MapHandler, telegohandler.CommandEqual("map"))
+	botHandler.Handle(handlers.GlobalMapHandler, telegohandler.CommandEqual("globalmap"))
+	botHandler.Handle(handlers.FilterHandler, telegohandler.CommandEqual("filter"))
+	botHandler.Handle(handlers.ExportHandler, telegohandler.CommandEqual("export"))
+	botHandler.Handle(handlers.WishlistHandler, telegohandler.CommandEqual("wishlist"))
+	botHandler.Handle(handlers.CompareHandler, telegohandler.CommandEqual("compare"))
+	botHandler.Handle(handlers.ChangesHandler, telegohandler.CommandEqual("changes"))
+
+	botHandler.Handle(handlers.ImportHandler, func(ctx context.Context, update telego.Update) bool {
+		return update.Message != nil && update.Message.Document != nil && handlers.IsImportDocument(*update.Message.Document)
+	})
 
 	// Debug command
 	botHandler.Handle(handlers.RefreshHandler, telegohandler.CommandEqual("refresh"))
@@ -0,0 +1,29 @@
+package configs
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Admins are listed in ADMIN_IDS as comma separated telegram user ids
+func IsAdmin(userId int64) bool {
+	for _, adminId := range strings.Split(os.Getenv("ADMIN_IDS"), ",") {
+		parsedAdminId, err := strconv.ParseInt(strings.TrimSpace(adminId), 10, 64)
+		if err == nil && parsedAdminId == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Chat for operational alerts is set in ADMIN_CHAT_ID, it can be a group or a private chat
+func GetAdminChatId() (int64, bool) {
+	adminChatId, err := strconv.ParseInt(strings.TrimSpace(os.Getenv("ADMIN_CHAT_ID")), 10, 64)
+	if err != nil || adminChatId == 0 {
+		return 0, false
+	}
+
+	return adminChatId, true
+}
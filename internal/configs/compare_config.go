@@ -0,0 +1,26 @@
+package configs
+
+import (
+	"os"
+	"strings"
+)
+
+const defaultCompareCountries = "us,gb,de,pl,br,in"
+
+// Regions to compare Steam prices in are listed in COMPARE_COUNTRIES as comma separated country codes
+func GetCompareCountries() []string {
+	value := os.Getenv("COMPARE_COUNTRIES")
+	if value == "" {
+		value = defaultCompareCountries
+	}
+
+	var countryCodes []string
+	for _, countryCode := range strings.Split(value, ",") {
+		countryCode = strings.ToLower(strings.TrimSpace(countryCode))
+		if countryCode != "" {
+			countryCodes = append(countryCodes, countryCode)
+		}
+	}
+
+	return countryCodes
+}
@@ -0,0 +1,29 @@
+package configs
+
+import "strings"
+
+// Steam sells in USD wherever it has no store currency of its own, Turkey and Argentina included
+const defaultSteamCurrency = "USD"
+
+// Store currency by ISO 3166 country code, only countries with their own Steam currency are listed
+var steamCurrencies = map[string]string{
+	"ae": "AED", "au": "AUD", "br": "BRL", "ca": "CAD", "ch": "CHF", "cl": "CLP", "cn": "CNY", "co": "COP",
+	"cr": "CRC", "gb": "GBP", "hk": "HKD", "id": "IDR", "il": "ILS", "in": "INR", "jp": "JPY", "kr": "KRW",
+	"kw": "KWD", "kz": "KZT", "mx": "MXN", "my": "MYR", "no": "NOK", "nz": "NZD", "pe": "PEN", "ph": "PHP",
+	"pl": "PLN", "qa": "QAR", "ru": "RUB", "sa": "SAR", "sg": "SGD", "th": "THB", "tw": "TWD", "ua": "UAH",
+	"uy": "UYU", "vn": "VND", "za": "ZAR",
+
+	// Euro store covers the eurozone and most of the rest of Europe
+	"at": "EUR", "be": "EUR", "bg": "EUR", "cy": "EUR", "cz": "EUR", "de": "EUR", "dk": "EUR", "ee": "EUR",
+	"es": "EUR", "fi": "EUR", "fr": "EUR", "gr": "EUR", "hr": "EUR", "hu": "EUR", "ie": "EUR", "it": "EUR",
+	"lt": "EUR", "lu": "EUR", "lv": "EUR", "mt": "EUR", "nl": "EUR", "pt": "EUR", "ro": "EUR", "se": "EUR",
+	"si": "EUR", "sk": "EUR",
+}
+
+func GetSteamCurrency(countryCode string) string {
+	if currency, isExists := steamCurrencies[strings.ToLower(countryCode)]; isExists {
+		return currency
+	}
+
+	return defaultSteamCurrency
+}
@@ -0,0 +1,68 @@
+package configs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+)
+
+// Rates come from a json file at EXCHANGE_RATES_FILE or, without it, from the given url.
+// Prices are not converted when neither is set
+func LoadExchangeRates(sourceUrl string) (models.ExchangeRates, error) {
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		return loadExchangeRatesFile(path)
+	}
+
+	if sourceUrl != "" {
+		return requestExchangeRates(sourceUrl)
+	}
+
+	return models.ExchangeRates{}, nil
+}
+
+func loadExchangeRatesFile(path string) (models.ExchangeRates, error) {
+	var rates models.ExchangeRates
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rates, errors.Join(errors.New("config: could not read exchange rates file:"), err)
+	}
+
+	if err = json.Unmarshal(data, &rates); err != nil {
+		return rates, errors.Join(errors.New("config: could not parse exchange rates file:"), err)
+	}
+
+	return rates, nil
+}
+
+func requestExchangeRates(sourceUrl string) (models.ExchangeRates, error) {
+	var rates models.ExchangeRates
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Get(sourceUrl)
+	if err != nil {
+		return rates, errors.Join(errors.New("config: could not request exchange rates:"), err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return rates, fmt.Errorf("config: exchange rates source responded with status: %d", response.StatusCode)
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return rates, errors.Join(errors.New("config: could not read exchange rates response:"), err)
+	}
+
+	if err = json.Unmarshal(data, &rates); err != nil {
+		return rates, errors.Join(errors.New("config: could not parse exchange rates response:"), err)
+	}
+
+	return rates, nil
+}
@@ -5,15 +5,98 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
+	"net/url"
+	"sync"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 )
 
-var igdbToken string
+const igdbTokenRefreshMargin = 24 * time.Hour
 
-func RequestIgdbToken() error {
-	response, err := http.Post(fmt.Sprintf("https://id.twitch.tv/oauth2/token?client_id=%s&client_secret=%s&grant_type=client_credentials", os.Getenv("IGDB_ID"), os.Getenv("IGDB_SECRET")), "text/plain", nil)
+var ErrIgdbInvalidCredentials = errors.New("config: igdb credentials are invalid, check IGDB_ID and IGDB_SECRET")
+
+type IgdbTokenSource interface {
+	ClientId() string
+	// Returns cached token, refreshing it if it is about to expire
+	GetToken() (string, error)
+	// Drops token rejected by igdb, so the next GetToken call requests a new one
+	InvalidateToken(rejectedToken string)
+}
+
+// Keeps twitch app token for igdb and refreshes it before it expires
+type IgdbTokenManager struct {
+	oauthBaseUrl string
+	clientId     string
+	clientSecret string
+	httpClient   *http.Client
+
+	mutex     sync.Mutex
+	token     string
+	refreshAt time.Time
+}
+
+func NewIgdbTokenManager(oauthBaseUrl string, clientId string, clientSecret string) *IgdbTokenManager {
+	return &IgdbTokenManager{
+		oauthBaseUrl: oauthBaseUrl,
+		clientId:     clientId,
+		clientSecret: clientSecret,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (m *IgdbTokenManager) ClientId() string {
+	return m.clientId
+}
+
+// Forces a fresh token, used at startup to fail fast on bad credentials
+func (m *IgdbTokenManager) RequestToken() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.refresh()
+}
+
+func (m *IgdbTokenManager) GetToken() (string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.token == "" || !time.Now().Before(m.refreshAt) {
+		if err := m.refresh(); err != nil {
+			return "", err
+		}
+	}
+
+	return m.token, nil
+}
+
+// Compares with the rejected token to not throw away one already refreshed by another caller
+func (m *IgdbTokenManager) InvalidateToken(rejectedToken string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.token == rejectedToken {
+		m.token = ""
+	}
+}
+
+func ConstructAdditionalHeadersForIgdb(clientId string, token string) map[string]string {
+	headers := make(map[string]string)
+
+	headers["Client-ID"] = clientId
+	headers["Authorization"] = fmt.Sprintf("Bearer %s", token)
+
+	return headers
+}
+
+// Must be called with mutex held
+func (m *IgdbTokenManager) refresh() error {
+	query := url.Values{}
+	query.Set("client_id", m.clientId)
+	query.Set("client_secret", m.clientSecret)
+	query.Set("grant_type", "client_credentials")
+
+	response, err := m.httpClient.Post(m.oauthBaseUrl+"/oauth2/token?"+query.Encode(), "text/plain", nil)
 	if err != nil {
 		return errors.Join(errors.New("config: could not make request to igdb to request token:"), err)
 	}
@@ -24,16 +107,30 @@ func RequestIgdbToken() error {
 		return errors.Join(errors.New("config: could not read response from igdb to request token:"), err)
 	}
 
-	igdbToken = jsoniter.Get(body, "access_token").ToString()
+	if response.StatusCode != http.StatusOK {
+		message := jsoniter.Get(body, "message").ToString()
 
-	return nil
-}
+		// Twitch answers bad client id with 400 and bad secret with 403
+		if response.StatusCode == http.StatusBadRequest || response.StatusCode == http.StatusForbidden {
+			return errors.Join(ErrIgdbInvalidCredentials, fmt.Errorf("config: twitch responded with status: %d %s", response.StatusCode, message))
+		}
 
-func ConstructAdditionalHeadersForIgdb() map[string]string {
-	headers := make(map[string]string)
+		return fmt.Errorf("config: could not request igdb token, twitch responded with status: %d %s", response.StatusCode, message)
+	}
+
+	token := jsoniter.Get(body, "access_token").ToString()
+	if token == "" {
+		return errors.New("config: could not find access token in twitch response")
+	}
 
-	headers["Client-ID"] = os.Getenv("IGDB_ID")
-	headers["Authorization"] = fmt.Sprintf("Bearer %s", igdbToken)
+	expiresIn := time.Duration(jsoniter.Get(body, "expires_in").ToInt64()) * time.Second
+	margin := igdbTokenRefreshMargin
+	if expiresIn/10 < margin {
+		margin = expiresIn / 10
+	}
 
-	return headers
+	m.token = token
+	m.refreshAt = time.Now().Add(expiresIn - margin)
+
+	return nil
 }
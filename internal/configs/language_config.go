@@ -0,0 +1,56 @@
+package configs
+
+import "strings"
+
+// Bot speaks English unless the user picks another language
+const DefaultLanguage = "en"
+
+// Steam api language names by the language codes Telegram reports for its clients
+var steamLanguages = map[string]string{
+	"ar":      "arabic",
+	"bg":      "bulgarian",
+	"cs":      "czech",
+	"da":      "danish",
+	"de":      "german",
+	"el":      "greek",
+	"en":      "english",
+	"es":      "spanish",
+	"es-419":  "latam",
+	"fi":      "finnish",
+	"fr":      "french",
+	"hu":      "hungarian",
+	"id":      "indonesian",
+	"it":      "italian",
+	"ja":      "japanese",
+	"ko":      "koreana",
+	"nb":      "norwegian",
+	"nl":      "dutch",
+	"no":      "norwegian",
+	"pl":      "polish",
+	"pt":      "portuguese",
+	"pt-br":   "brazilian",
+	"ro":      "romanian",
+	"ru":      "russian",
+	"sv":      "swedish",
+	"th":      "thai",
+	"tr":      "turkish",
+	"uk":      "ukrainian",
+	"vi":      "vietnamese",
+	"zh":      "schinese",
+	"zh-hans": "schinese",
+	"zh-hant": "tchinese",
+}
+
+func IsSupportedLanguage(languageCode string) bool {
+	_, isExists := steamLanguages[strings.ToLower(languageCode)]
+	return isExists
+}
+
+// Unknown and empty codes fall back to English, the language Steam answers in by default
+func GetSteamLanguage(languageCode string) string {
+	if steamLanguage, isExists := steamLanguages[strings.ToLower(languageCode)]; isExists {
+		return steamLanguage
+	}
+
+	return steamLanguages[DefaultLanguage]
+}
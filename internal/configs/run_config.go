@@ -0,0 +1,85 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRunConcurrency    = 4
+	defaultRunDeadline       = time.Hour
+	defaultRunExtrasDeadline = 15 * time.Minute
+)
+
+// Shape of a scheduled run, upstream rate limits still apply to all workers together
+type RunConfig struct {
+	// Users processed at the same time, set in RUN_CONCURRENCY
+	Concurrency int
+	// Time collecting wishlists and prices may take, set in RUN_DEADLINE as a duration like 45m
+	Deadline time.Duration
+	// Time reviews, localized names and region comparison may take after that, set in RUN_EXTRAS_DEADLINE.
+	// Digests go out either way, running out of it only leaves the extras off
+	ExtrasDeadline time.Duration
+}
+
+func DefaultRunConfig() RunConfig {
+	return RunConfig{
+		Concurrency:    defaultRunConcurrency,
+		Deadline:       defaultRunDeadline,
+		ExtrasDeadline: defaultRunExtrasDeadline,
+	}
+}
+
+func LoadRunConfig() (RunConfig, error) {
+	runConfig := DefaultRunConfig()
+
+	if value := strings.TrimSpace(os.Getenv("RUN_CONCURRENCY")); value != "" {
+		concurrency, err := strconv.Atoi(value)
+		if err != nil {
+			return runConfig, errors.Join(fmt.Errorf("config: could not parse RUN_CONCURRENCY: %s", value), err)
+		}
+
+		if concurrency < 1 {
+			return runConfig, fmt.Errorf("config: RUN_CONCURRENCY must be at least 1: %d", concurrency)
+		}
+
+		runConfig.Concurrency = concurrency
+	}
+
+	deadline, err := loadRunDuration("RUN_DEADLINE", runConfig.Deadline)
+	if err != nil {
+		return runConfig, err
+	}
+	runConfig.Deadline = deadline
+
+	extrasDeadline, err := loadRunDuration("RUN_EXTRAS_DEADLINE", runConfig.ExtrasDeadline)
+	if err != nil {
+		return runConfig, err
+	}
+	runConfig.ExtrasDeadline = extrasDeadline
+
+	return runConfig, nil
+}
+
+// Unset keeps the default
+func loadRunDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return defaultValue, errors.Join(fmt.Errorf("config: could not parse %s: %s", key, value), err)
+	}
+
+	if duration <= 0 {
+		return defaultValue, fmt.Errorf("config: %s must be positive: %s", key, value)
+	}
+
+	return duration, nil
+}
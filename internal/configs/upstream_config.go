@@ -0,0 +1,39 @@
+package configs
+
+import (
+	"os"
+	"strings"
+)
+
+// Base urls of upstream services, overridable through env to point the bot at fake servers
+type UpstreamUrls struct {
+	Igdb           string
+	SteamStore     string
+	SteamApi       string
+	SteamCommunity string
+	Backloggd      string
+	TwitchOauth    string
+	// Full url of a json endpoint with exchange rates, conversion is off when empty
+	ExchangeRates string
+}
+
+func LoadUpstreamUrls() UpstreamUrls {
+	return UpstreamUrls{
+		Igdb:           getEnvOrDefault("IGDB_BASE_URL", "https://api.igdb.com/v4"),
+		SteamStore:     getEnvOrDefault("STEAM_STORE_BASE_URL", "https://store.steampowered.com"),
+		SteamApi:       getEnvOrDefault("STEAM_API_BASE_URL", "https://api.steampowered.com"),
+		SteamCommunity: getEnvOrDefault("STEAM_COMMUNITY_BASE_URL", "https://steamcommunity.com"),
+		Backloggd:      getEnvOrDefault("BACKLOGGD_BASE_URL", "https://backloggd.com"),
+		TwitchOauth:    getEnvOrDefault("TWITCH_OAUTH_BASE_URL", "https://id.twitch.tv"),
+		ExchangeRates:  getEnvOrDefault("EXCHANGE_RATES_URL", ""),
+	}
+}
+
+func getEnvOrDefault(key string, defaultValue string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	return strings.TrimSuffix(value, "/")
+}
@@ -0,0 +1,50 @@
+package fakes
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// Serves the minimal markup backloggd parser relies on
+func newBackloggdHandler(fixtures Fixtures) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /u/{username}/", func(w http.ResponseWriter, r *http.Request) {
+		username := r.PathValue("username")
+		if _, isExists := fixtures.BackloggdWishlists[username]; !isExists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(w, `<html><body><a href="/u/%s/games/">Games</a></body></html>`, html.EscapeString(username))
+	})
+
+	mux.HandleFunc("GET /u/{username}/games/", func(w http.ResponseWriter, r *http.Request) {
+		username := r.PathValue("username")
+		if _, isExists := fixtures.BackloggdWishlists[username]; !isExists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		fmt.Fprintf(w, `<html><body><a href="/u/%s/games/added/type:wishlist/">Wishlist</a></body></html>`, html.EscapeString(username))
+	})
+
+	mux.HandleFunc("GET /u/{username}/games/added/type:wishlist/", func(w http.ResponseWriter, r *http.Request) {
+		games, isExists := fixtures.BackloggdWishlists[r.PathValue("username")]
+		if !isExists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var links strings.Builder
+		for _, game := range games {
+			fmt.Fprintf(&links, `<a href="/games/%s/"><img alt="%s"></a>`, html.EscapeString(game.Slug), html.EscapeString(game.Title))
+		}
+
+		fmt.Fprintf(w, `<html><body><div id="game-lists">%s</div></body></html>`, links.String())
+	})
+
+	return mux
+}
@@ -0,0 +1,17 @@
+package fakes
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func newExchangeRatesHandler(fixtures Fixtures) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /rates", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(fixtures.ExchangeRates)
+	})
+
+	return mux
+}
@@ -0,0 +1,112 @@
+package fakes
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+)
+
+var (
+	igdbSearchRegexp    = regexp.MustCompile(`search "((?:[^"\\]|\\.)*)"`)
+	igdbSlugRegexp      = regexp.MustCompile(`where slug = \(([^)]*)\)`)
+	igdbIdRegexp        = regexp.MustCompile(`where id = \(([^)]*)\)`)
+	igdbUidRegexp       = regexp.MustCompile(`external_games\.uid = \(([^)]*)\)`)
+	igdbAltNameRegexp   = regexp.MustCompile(`alternative_names\.name ~ "((?:[^"\\]|\\.)*)"`)
+	igdbQuotedRegexp    = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+	igdbUnescapeReplace = strings.NewReplacer(`\"`, `"`, `\\`, `\`)
+)
+
+// Understands only the apicalypse queries the bot sends
+func newIgdbHandler(fixtures Fixtures) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v4/games", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Client-ID") != IgdbClientId || r.Header.Get("Authorization") != "Bearer "+IgdbToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		payload := string(body)
+
+		games := []igdb.Game{}
+		for _, game := range fixtures.IgdbGames {
+			if matchesIgdbPayload(game, payload) {
+				games = append(games, game)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(games)
+	})
+
+	return mux
+}
+
+func matchesIgdbPayload(game igdb.Game, payload string) bool {
+	if match := igdbSearchRegexp.FindStringSubmatch(payload); match != nil {
+		return strings.Contains(strings.ToLower(game.Name), strings.ToLower(igdbUnescapeReplace.Replace(match[1])))
+	}
+
+	if match := igdbAltNameRegexp.FindStringSubmatch(payload); match != nil {
+		for _, alternativeName := range game.AlternativeNames {
+			if strings.EqualFold(alternativeName.Name, igdbUnescapeReplace.Replace(match[1])) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if match := igdbSlugRegexp.FindStringSubmatch(payload); match != nil {
+		for _, slug := range getIgdbQuotedValues(match[1]) {
+			if game.Slug == slug {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if match := igdbIdRegexp.FindStringSubmatch(payload); match != nil {
+		for _, id := range strings.Split(match[1], ",") {
+			if strings.TrimSpace(id) == strconv.FormatUint(game.Id, 10) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if match := igdbUidRegexp.FindStringSubmatch(payload); match != nil {
+		for _, uid := range getIgdbQuotedValues(match[1]) {
+			for _, externalGame := range game.ExternalGames {
+				if externalGame.ExternalGameSource.Id == igdb.ExternalGameSourceSteam && externalGame.Uid == uid {
+					return true
+				}
+			}
+		}
+
+		return false
+	}
+
+	return false
+}
+
+func getIgdbQuotedValues(list string) []string {
+	var values []string
+	for _, match := range igdbQuotedRegexp.FindAllStringSubmatch(list, -1) {
+		values = append(values, igdbUnescapeReplace.Replace(match[1]))
+	}
+
+	return values
+}
@@ -0,0 +1,266 @@
+package fakes
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// Keeps run data in memory and filters it the way repos query mongo
+type RunStore struct {
+	mutex            sync.Mutex
+	wishlists        map[int64]models.Wishlist
+	watchlistEntries []models.WatchlistEntry
+	igdbGames        []igdb.Game
+	slugMatches      map[string]models.SlugMatch
+	storeOverrides   []models.StoreOverride
+	releaseWatches   []models.ReleaseWatch
+	steamAppsDetails []steam.AppDetails
+	reviewSummaries  map[uint64]steam.ReviewSummary
+	localizedNames   []steam.LocalizedName
+}
+
+func NewRunStore() *RunStore {
+	return &RunStore{
+		wishlists:       make(map[int64]models.Wishlist),
+		slugMatches:     make(map[string]models.SlugMatch),
+		reviewSummaries: make(map[uint64]steam.ReviewSummary),
+	}
+}
+
+// Seeds data runs only read, like entries added with /add or mappings made with /map
+func (s *RunStore) AddWatchlistEntry(entry models.WatchlistEntry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.watchlistEntries = append(s.watchlistEntries, entry)
+}
+
+func (s *RunStore) AddStoreOverride(override models.StoreOverride) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.storeOverrides = append(s.storeOverrides, override)
+}
+
+func (s *RunStore) GetWishlist(userId int64) (*models.Wishlist, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	wishlist, isExists := s.wishlists[userId]
+	if !isExists {
+		return nil, nil
+	}
+
+	return &wishlist, nil
+}
+
+func (s *RunStore) UpsertWishlist(wishlist models.Wishlist) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.wishlists[wishlist.UserId] = wishlist
+	return nil
+}
+
+func (s *RunStore) GetWatchlist(userId int64) ([]models.WatchlistEntry, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var results []models.WatchlistEntry
+	for _, entry := range s.watchlistEntries {
+		if entry.UserId == userId {
+			results = append(results, entry)
+		}
+	}
+
+	return results, nil
+}
+
+func (s *RunStore) GetIgdbGames(slugs []string) ([]igdb.Game, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var results []igdb.Game
+	for _, game := range s.igdbGames {
+		if slices.Contains(slugs, game.Slug) {
+			results = append(results, game)
+		}
+	}
+
+	return results, nil
+}
+
+func (s *RunStore) InsertIgdbGames(games []igdb.Game) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.igdbGames = append(s.igdbGames, games...)
+	return nil
+}
+
+func (s *RunStore) GetSlugMatches(backloggdSlugs []string) ([]models.SlugMatch, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var results []models.SlugMatch
+	for _, slug := range backloggdSlugs {
+		if match, isExists := s.slugMatches[slug]; isExists {
+			results = append(results, match)
+		}
+	}
+
+	return results, nil
+}
+
+// Notified users survive the upsert, the same way $set leaves them alone
+func (s *RunStore) UpsertSlugMatch(match models.SlugMatch) (*models.SlugMatch, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if existingMatch, isExists := s.slugMatches[match.BackloggdSlug]; isExists {
+		match.Id = existingMatch.Id
+		match.NotifiedUserIds = existingMatch.NotifiedUserIds
+	} else {
+		match.Id = bson.NewObjectID()
+		match.NotifiedUserIds = nil
+	}
+
+	s.slugMatches[match.BackloggdSlug] = match
+	return &match, nil
+}
+
+func (s *RunStore) GetStoreOverrides(userId int64, slugs []string) ([]models.StoreOverride, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var results []models.StoreOverride
+	for _, override := range s.storeOverrides {
+		isOwned := override.UserId == userId || override.UserId == models.GlobalOverrideUserId
+		if isOwned && slices.Contains(slugs, override.Slug) {
+			results = append(results, override)
+		}
+	}
+
+	return results, nil
+}
+
+func (s *RunStore) GetReleaseWatches(userId int64) ([]models.ReleaseWatch, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var results []models.ReleaseWatch
+	for _, watch := range s.releaseWatches {
+		if watch.UserId == userId {
+			results = append(results, watch)
+		}
+	}
+
+	return results, nil
+}
+
+func (s *RunStore) UpsertReleaseWatch(watch models.ReleaseWatch) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, existingWatch := range s.releaseWatches {
+		if existingWatch.UserId == watch.UserId && existingWatch.Slug == watch.Slug {
+			s.releaseWatches[i] = watch
+			return nil
+		}
+	}
+
+	s.releaseWatches = append(s.releaseWatches, watch)
+	return nil
+}
+
+func (s *RunStore) DeleteReleaseWatch(userId int64, slug string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.releaseWatches = slices.DeleteFunc(s.releaseWatches, func(watch models.ReleaseWatch) bool {
+		return watch.UserId == userId && watch.Slug == slug
+	})
+
+	return nil
+}
+
+func (s *RunStore) GetSteamAppsDetails(appIds []uint64, countryCode string) ([]steam.AppDetails, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var results []steam.AppDetails
+	for _, appDetails := range s.steamAppsDetails {
+		if appDetails.CountryCode == countryCode && slices.Contains(appIds, appDetails.SteamAppId) {
+			results = append(results, appDetails)
+		}
+	}
+
+	return results, nil
+}
+
+func (s *RunStore) InsertSteamAppsDetails(steamAppsDetails []steam.AppDetails) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.steamAppsDetails = append(s.steamAppsDetails, steamAppsDetails...)
+	return nil
+}
+
+func (s *RunStore) GetSteamReviewSummaries(steamAppsIds []uint64, checkedAfter time.Time) ([]steam.ReviewSummary, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var results []steam.ReviewSummary
+	for _, steamAppId := range steamAppsIds {
+		reviewSummary, isExists := s.reviewSummaries[steamAppId]
+		if isExists && !reviewSummary.CheckedAt.Before(checkedAfter) {
+			results = append(results, reviewSummary)
+		}
+	}
+
+	return results, nil
+}
+
+func (s *RunStore) UpsertSteamReviewSummary(reviewSummary steam.ReviewSummary) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.reviewSummaries[reviewSummary.SteamAppId] = reviewSummary
+	return nil
+}
+
+func (s *RunStore) GetSteamLocalizedNames(steamAppsIds []uint64, language string, checkedAfter time.Time) ([]steam.LocalizedName, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var results []steam.LocalizedName
+	for _, localizedName := range s.localizedNames {
+		isFresh := !localizedName.CheckedAt.Before(checkedAfter)
+		if localizedName.Language == language && isFresh && slices.Contains(steamAppsIds, localizedName.SteamAppId) {
+			results = append(results, localizedName)
+		}
+	}
+
+	return results, nil
+}
+
+func (s *RunStore) UpsertSteamLocalizedName(localizedName steam.LocalizedName) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for i, existingName := range s.localizedNames {
+		if existingName.SteamAppId == localizedName.SteamAppId && existingName.Language == localizedName.Language {
+			s.localizedNames[i] = localizedName
+			return nil
+		}
+	}
+
+	s.localizedNames = append(s.localizedNames, localizedName)
+	return nil
+}
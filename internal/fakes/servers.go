@@ -0,0 +1,88 @@
+// Package fakes runs in-process http servers that mimic upstream services and keeps
+// run data in memory, so the notification pipeline can be exercised without a network or mongo.
+package fakes
+
+import (
+	"net/http/httptest"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+)
+
+const (
+	IgdbClientId     = "fake-client-id"
+	IgdbClientSecret = "fake-client-secret"
+	IgdbToken        = "fake-token"
+)
+
+// Data served by fake upstreams, must not be changed after servers start
+type Fixtures struct {
+	IgdbGames []igdb.Game
+	// Apps missing here are answered with success false, like delisted ones
+	SteamAppsDetails map[uint64]steam.AppDetails
+	// App names by Steam language, apps missing here are answered in English
+	SteamLocalizedNames map[string]map[uint64]string
+	// Same for packages, missing bundles are left out of the response
+	SteamPackagesDetails map[uint64]steam.PackageDetails
+	SteamBundlesDetails  map[uint64]steam.BundleDetails
+	// Apps missing here have no reviews yet
+	SteamReviewSummaries map[uint64]steam.ReviewSummary
+	// Steam app ids by SteamID64
+	SteamWishlists map[string][]uint64
+	// SteamID64 by vanity name
+	SteamVanityNames map[string]string
+	// Games by Backloggd username
+	BackloggdWishlists map[string][]models.ScrapedGame
+	ExchangeRates      models.ExchangeRates
+}
+
+type Servers struct {
+	Twitch         *httptest.Server
+	Igdb           *httptest.Server
+	SteamStore     *httptest.Server
+	SteamApi       *httptest.Server
+	SteamCommunity *httptest.Server
+	Backloggd      *httptest.Server
+	ExchangeRates  *httptest.Server
+}
+
+func NewServers(fixtures Fixtures) *Servers {
+	return &Servers{
+		Twitch:         httptest.NewServer(newTwitchHandler()),
+		Igdb:           httptest.NewServer(newIgdbHandler(fixtures)),
+		SteamStore:     httptest.NewServer(newSteamStoreHandler(fixtures)),
+		SteamApi:       httptest.NewServer(newSteamApiHandler(fixtures)),
+		SteamCommunity: httptest.NewServer(newSteamCommunityHandler(fixtures)),
+		Backloggd:      httptest.NewServer(newBackloggdHandler(fixtures)),
+		ExchangeRates:  httptest.NewServer(newExchangeRatesHandler(fixtures)),
+	}
+}
+
+func (s *Servers) Urls() configs.UpstreamUrls {
+	return configs.UpstreamUrls{
+		Igdb:           s.Igdb.URL + "/v4",
+		SteamStore:     s.SteamStore.URL,
+		SteamApi:       s.SteamApi.URL,
+		SteamCommunity: s.SteamCommunity.URL,
+		Backloggd:      s.Backloggd.URL,
+		TwitchOauth:    s.Twitch.URL,
+		ExchangeRates:  s.ExchangeRates.URL + "/rates",
+	}
+}
+
+// Profile url the Backloggd parser accepts for the given username
+func (s *Servers) BackloggdProfileUrl(username string) string {
+	return s.Backloggd.URL + "/u/" + username + "/"
+}
+
+func (s *Servers) Close() {
+	s.Twitch.Close()
+	s.Igdb.Close()
+	s.SteamStore.Close()
+	s.SteamApi.Close()
+	s.SteamCommunity.Close()
+	s.Backloggd.Close()
+	s.ExchangeRates.Close()
+}
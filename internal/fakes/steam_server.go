@@ -0,0 +1,135 @@
+package fakes
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+)
+
+func newSteamStoreHandler(fixtures Fixtures) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/appdetails/", func(w http.ResponseWriter, r *http.Request) {
+		appId := r.URL.Query().Get("appids")
+		parsedAppId, err := strconv.ParseUint(appId, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		appDetails, isExists := fixtures.SteamAppsDetails[parsedAppId]
+		if !isExists {
+			json.NewEncoder(w).Encode(map[string]any{appId: map[string]any{"success": false}})
+			return
+		}
+
+		if localizedName, isExists := fixtures.SteamLocalizedNames[r.URL.Query().Get("l")][parsedAppId]; isExists {
+			appDetails.Name = localizedName
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{appId: map[string]any{"success": true, "data": appDetails}})
+	})
+
+	mux.HandleFunc("GET /api/packagedetails/", func(w http.ResponseWriter, r *http.Request) {
+		packageId := r.URL.Query().Get("packageids")
+		parsedPackageId, err := strconv.ParseUint(packageId, 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		packageDetails, isExists := fixtures.SteamPackagesDetails[parsedPackageId]
+		if !isExists {
+			json.NewEncoder(w).Encode(map[string]any{packageId: map[string]any{"success": false}})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{packageId: map[string]any{"success": true, "data": packageDetails}})
+	})
+
+	mux.HandleFunc("GET /appreviews/{appid}", func(w http.ResponseWriter, r *http.Request) {
+		parsedAppId, err := strconv.ParseUint(r.PathValue("appid"), 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		reviewSummary, isExists := fixtures.SteamReviewSummaries[parsedAppId]
+		if !isExists {
+			reviewSummary = steam.ReviewSummary{ScoreDescription: "No user reviews"}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"success": 1, "query_summary": reviewSummary})
+	})
+
+	mux.HandleFunc("GET /actions/ajaxresolvebundles", func(w http.ResponseWriter, r *http.Request) {
+		bundlesDetails := []steam.BundleDetails{}
+		for _, bundleId := range strings.Split(r.URL.Query().Get("bundleids"), ",") {
+			parsedBundleId, err := strconv.ParseUint(bundleId, 10, 64)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if bundleDetails, isExists := fixtures.SteamBundlesDetails[parsedBundleId]; isExists {
+				bundlesDetails = append(bundlesDetails, bundleDetails)
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(bundlesDetails)
+	})
+
+	return mux
+}
+
+func newSteamApiHandler(fixtures Fixtures) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /IWishlistService/GetWishlist/v1/", func(w http.ResponseWriter, r *http.Request) {
+		// Profiles missing here are treated as private
+		appIds, isExists := fixtures.SteamWishlists[r.URL.Query().Get("steamid")]
+		if !isExists {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		var items []map[string]any
+		for i, appId := range appIds {
+			items = append(items, map[string]any{"appid": appId, "priority": i + 1, "date_added": 0})
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{"response": map[string]any{"items": items}})
+	})
+
+	return mux
+}
+
+func newSteamCommunityHandler(fixtures Fixtures) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /id/{vanity}/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+
+		steamId64, isExists := fixtures.SteamVanityNames[strings.ToLower(r.PathValue("vanity"))]
+		if !isExists {
+			fmt.Fprint(w, `<?xml version="1.0" encoding="UTF-8"?><response><error><![CDATA[The specified profile could not be found.]]></error></response>`)
+			return
+		}
+
+		fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><profile><steamID64>%s</steamID64></profile>`, steamId64)
+	})
+
+	return mux
+}
@@ -0,0 +1,35 @@
+package fakes
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+func newTwitchHandler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+
+		if query.Get("client_id") != IgdbClientId {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]any{"status": 400, "message": "invalid client"})
+			return
+		}
+
+		if query.Get("client_secret") != IgdbClientSecret {
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(map[string]any{"status": 403, "message": "invalid client secret"})
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": IgdbToken,
+			"expires_in":   5184000,
+			"token_type":   "bearer",
+		})
+	})
+
+	return mux
+}
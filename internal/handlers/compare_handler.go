@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/mymmrac/telego/telegoutil"
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+const compareUsage = "Boss, tell me what game to compare prices for using the /compare <game> command. The game is a Steam link or a title. " +
+	"Use /compare <on|off> to see cheaper regions for your deals in the digest, or /compare on us,de to pick the regions."
+
+type regionalPrice struct {
+	countryCode  string
+	availability steam.Availability
+	// Set when the region could not be checked at all
+	err   error
+	price types.Money
+	// Price in the comparison currency
+	converted types.Money
+	// False when there is no exchange rate for the currency
+	isConverted bool
+}
+
+type priceComparison struct {
+	name string
+	// Display currency of the user or currency of their own region, others are converted to it
+	currency string
+	// Cheapest first, regions without a converted price go last
+	prices []regionalPrice
+}
+
+func CompareHandler(ctx *telegohandler.Context, update telego.Update) error {
+	argument := getCommandArgument(update.Message.Text)
+	if argument == "" {
+		if err := sendMessage(ctx, update, compareUsage); err != nil {
+			return errors.Join(errors.New("handler: could not handle /compare command: not enough arguments:"), err)
+		}
+
+		return nil
+	}
+
+	// Titles starting with "on" or "off" are still searched, unless the rest reads as regions
+	if compareRegions, compareCountries, isSetting := parseCompareRegionsSetting(argument); isSetting {
+		if err := handleCompareRegionsSetting(ctx, update, compareRegions, compareCountries); err != nil {
+			return errors.Join(errors.New("handler: could not handle /compare command:"), err)
+		}
+
+		return nil
+	}
+
+	steamAppId, err := resolveCompareSteamAppId(ctx, argument)
+	if err != nil {
+		message := "Couldn't search for this game for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /compare command: could not resolve game:"), err)
+		}
+
+		return nil
+	}
+
+	if steamAppId == 0 {
+		message := "Couldn't find this game on Steam. Try a Steam link instead, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /compare command: nothing found:"), err)
+		}
+
+		return nil
+	}
+
+	userSettings, err := repos.GetUserSettingsByUserId(update.Message.Chat.ID)
+	if err != nil {
+		return errors.Join(errors.New("handler: could not handle /compare command: could not get user settings:"), err)
+	}
+
+	// New users have no settings yet, regions are compared without a home country then
+	var countryCode, displayCurrency, language string
+	compareCountries := configs.GetCompareCountries()
+	if userSettings != nil {
+		countryCode = userSettings.CountryCode
+		displayCurrency = userSettings.DisplayCurrency
+		language = userSettings.Language
+		compareCountries = getCompareCountries(*userSettings)
+	}
+
+	// Steam store is slow to ask, so let the user know it is on the way
+	if err := sendMessage(ctx, update, "Checking prices around the world, give me a minute, boss."); err != nil {
+		return errors.Join(errors.New("handler: could not handle /compare command: could not send progress message:"), err)
+	}
+
+	comparison := compareRegionalPrices(ctx, steamAppId, countryCode, displayCurrency, compareCountries)
+	if _, err := ctx.Bot().SendMessage(ctx, telegoutil.Message(
+		telegoutil.ID(update.Message.Chat.ID),
+		formatPriceComparison(comparison, countryCode, language),
+	).WithParseMode("HTML")); err != nil {
+		return errors.Join(errors.New("handler: could not handle /compare command: could not send comparison:"), err)
+	}
+
+	return nil
+}
+
+// Regions follow "on" as country codes, like "on us,de"
+func parseCompareRegionsSetting(argument string) (bool, []string, bool) {
+	fields := strings.Fields(strings.ToLower(strings.ReplaceAll(argument, ",", " ")))
+	switch {
+	case len(fields) == 1 && fields[0] == "off":
+		return false, nil, true
+	case len(fields) == 0 || fields[0] != "on":
+		return false, nil, false
+	}
+
+	for _, countryCode := range fields[1:] {
+		if !isCountryCode(countryCode) {
+			return false, nil, false
+		}
+	}
+
+	return true, fields[1:], true
+}
+
+func handleCompareRegionsSetting(ctx *telegohandler.Context, update telego.Update, compareRegions bool, compareCountries []string) error {
+	if err := repos.UpsertCompareRegionsSetting(update.Message.Chat.ID, compareRegions, compareCountries); err != nil {
+		message := "Couldn't update your compare setting for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("could not upsert setting:"), err)
+		}
+
+		return nil
+	}
+
+	message := "Got it, boss. Your digest will stick to your own region."
+	if compareRegions {
+		message = "Got it, boss. I will tell you when your deals are cheaper in other regions."
+	}
+
+	if err := sendMessage(ctx, update, message); err != nil {
+		return errors.Join(errors.New("send confirmation message:"), err)
+	}
+
+	return nil
+}
+
+// Zero means the game was not found on Steam
+func resolveCompareSteamAppId(ctx context.Context, game string) (uint64, error) {
+	if steamItemId, isParsed := parseSteamItemId(game); isParsed {
+		if steamItemId.Kind == steam.ItemApp {
+			return steamItemId.Id, nil
+		}
+
+		return 0, nil
+	}
+
+	games, err := upstreams.Igdb.SearchGames(ctx, game, igdbSearchLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, igdbGame := range games {
+		if steamAppsIds := getSteamAppsIds(igdbGame); len(steamAppsIds) > 0 {
+			return steamAppsIds[0], nil
+		}
+	}
+
+	return 0, nil
+}
+
+// Regions the user opted into, or every configured one
+func getCompareCountries(userSettings models.UserSettings) []string {
+	if len(userSettings.CompareCountries) > 0 {
+		return userSettings.CompareCountries
+	}
+
+	return configs.GetCompareCountries()
+}
+
+// User's own region goes first, so its currency is known before converting the rest
+func compareRegionalPrices(ctx context.Context, steamAppId uint64, countryCode string, displayCurrency string, compareCountries []string) priceComparison {
+	rates := getExchangeRates()
+
+	var comparison priceComparison
+	if rates.IsKnown(displayCurrency) {
+		comparison.currency = displayCurrency
+	} else if countryCode != "" {
+		comparison.currency = configs.GetSteamCurrency(countryCode)
+	}
+
+	seenCountryCodes := make(map[string]bool)
+	for _, regionCountryCode := range append([]string{strings.ToLower(countryCode)}, compareCountries...) {
+		if regionCountryCode == "" || seenCountryCodes[regionCountryCode] {
+			continue
+		}
+		seenCountryCodes[regionCountryCode] = true
+
+		price := regionalPrice{countryCode: regionCountryCode}
+
+		// Goes through the run cache, so regions already priced for other users are not requested again
+		appsDetails, err := obtainSteamAppsDetails(ctx, []uint64{steamAppId}, regionCountryCode)
+		if err != nil {
+			log.Printf("handler: could not check steam app %d in region %s: %v", steamAppId, regionCountryCode, err)
+			price.err = err
+		}
+
+		if len(appsDetails) > 0 {
+			appDetails := appsDetails[0]
+			if comparison.name == "" {
+				comparison.name = appDetails.Name
+			}
+
+			price.availability = appDetails.GetAvailability()
+			if appDetails.PriceOverview != nil {
+				price.price = types.NewMoney(int64(appDetails.PriceOverview.Final), appDetails.PriceOverview.Currency)
+			}
+		}
+
+		if comparison.currency == "" && price.price.Currency != "" {
+			comparison.currency = price.price.Currency
+		}
+
+		if price.price.Currency != "" {
+			price.converted, price.isConverted = rates.ConvertMoney(price.price, comparison.currency)
+		}
+
+		comparison.prices = append(comparison.prices, price)
+	}
+
+	if comparison.name == "" {
+		comparison.name = fmt.Sprintf("Steam app %d", steamAppId)
+	}
+
+	sort.SliceStable(comparison.prices, func(i, j int) bool {
+		if comparison.prices[i].isConverted != comparison.prices[j].isConverted {
+			return comparison.prices[i].isConverted
+		}
+
+		return comparison.prices[i].converted.Hundredths < comparison.prices[j].converted.Hundredths
+	})
+
+	return comparison
+}
+
+func formatPriceComparison(comparison priceComparison, countryCode string, language string) string {
+	// Sent with html parse mode, store titles often carry "&"
+	name := html.EscapeString(comparison.name)
+	lines := []string{fmt.Sprintf("<b>%s</b> around the world:", name)}
+	if comparison.currency != "" {
+		lines[0] = fmt.Sprintf("<b>%s</b> around the world, in %s:", name, comparison.currency)
+	}
+
+	for i, price := range comparison.prices {
+		line := strings.ToUpper(price.countryCode) + ": " + formatRegionalPrice(price, comparison.currency, language)
+		if i == 0 && price.isConverted && len(comparison.prices) > 1 && comparison.prices[1].isConverted {
+			line += " - cheapest"
+		}
+
+		if strings.EqualFold(price.countryCode, countryCode) {
+			line += " (you)"
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatRegionalPrice(price regionalPrice, currency string, language string) string {
+	switch {
+	case price.err != nil:
+		return "couldn't check"
+	case price.availability == steam.AvailabilityFree:
+		return "free to play"
+	case price.availability == steam.AvailabilityComingSoon:
+		return "coming soon"
+	case price.availability == steam.AvailabilityUnavailable || price.price.Currency == "":
+		return "not sold"
+	case !price.isConverted:
+		return price.price.FormatLocale(language)
+	case price.price.Currency == currency:
+		return price.converted.FormatLocale(language)
+	default:
+		return fmt.Sprintf("%s (%s)", price.converted.FormatLocale(language), price.price.FormatLocale(language))
+	}
+}
+
+// Prices compared apps up front in the regions users opted into, digests then read them from the cache
+func prefetchRegionalPrices(ctx context.Context, steamAppsIdsByCountry map[string][]uint64) {
+	for countryCode, steamAppsIds := range steamAppsIdsByCountry {
+		if err := fillSteamAppsDetails(ctx, uniqueSteamAppsIds(steamAppsIds), countryCode); err != nil {
+			log.Printf("handler: could not prefetch steam prices in region %s: %v", countryCode, err)
+		}
+	}
+}
+
+// Digest lines for deals that are even cheaper in another region
+func findCheaperRegions(ctx context.Context, sales []models.Sale, userSettings models.UserSettings) []string {
+	countryCode := userSettings.CountryCode
+	compareCountries := getCompareCountries(userSettings)
+
+	var lines []string
+	for _, sale := range sales {
+		if sale.SteamAppId == 0 {
+			continue
+		}
+
+		comparison := compareRegionalPrices(ctx, sale.SteamAppId, countryCode, userSettings.DisplayCurrency, compareCountries)
+		if len(comparison.prices) < 2 || !comparison.prices[0].isConverted || strings.EqualFold(comparison.prices[0].countryCode, countryCode) {
+			continue
+		}
+
+		for _, price := range comparison.prices {
+			if strings.EqualFold(price.countryCode, countryCode) && price.isConverted {
+				cheapest := comparison.prices[0]
+				lines = append(lines, fmt.Sprintf("<b>%s</b> is %s in %s, %s here", html.EscapeString(sale.Name), cheapest.converted.FormatLocale(userSettings.Language), strings.ToUpper(cheapest.countryCode), price.converted.FormatLocale(userSettings.Language)))
+			}
+		}
+	}
+
+	return lines
+}
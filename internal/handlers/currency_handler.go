@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+)
+
+func CurrencyHandler(ctx *telegohandler.Context, update telego.Update) error {
+	currency := strings.ToUpper(getCommandArgument(update.Message.Text))
+	if currency == "" {
+		message := "Boss, tell me what currency you want to see prices in using the /currency <code> command, like /currency EUR. Use /currency reset to stick to Steam prices."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /currency command: not enough arguments:"), err)
+		}
+
+		return nil
+	}
+
+	if currency == "RESET" {
+		currency = ""
+	} else if len(currency) != 3 || !getExchangeRates().IsKnown(currency) {
+		message := "I have no exchange rate for this currency. Try another one, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /currency command: unknown currency:"), err)
+		}
+
+		return nil
+	}
+
+	if err := repos.UpsertDisplayCurrencySetting(update.Message.Chat.ID, currency); err != nil {
+		message := "Couldn't update your currency for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /currency command: could not upsert setting:"), err)
+		}
+
+		return nil
+	}
+
+	message := "Got it, boss. I will show prices the way Steam does."
+	if currency != "" {
+		message = fmt.Sprintf("Got it, boss. I will also show prices in %s.", currency)
+	}
+
+	if err := sendMessage(ctx, update, message); err != nil {
+		return errors.Join(errors.New("handler: could not handle /currency command: send confirmation message:"), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+// One priced store item of a game: the game itself, another edition, a package or a bundle
+type steamEdition struct {
+	item            steam.ItemId
+	name            string
+	image           string
+	description     string
+	discountPercent int
+	initial         types.Money
+	final           types.Money
+}
+
+func splitSteamItemIds(itemIds []steam.ItemId) (appsIds []uint64, packagesIds []uint64, bundlesIds []uint64) {
+	seenItemIds := make(map[steam.ItemId]bool)
+	for _, itemId := range itemIds {
+		if seenItemIds[itemId] {
+			continue
+		}
+		seenItemIds[itemId] = true
+
+		switch itemId.Kind {
+		case steam.ItemApp:
+			appsIds = append(appsIds, itemId.Id)
+		case steam.ItemSub:
+			packagesIds = append(packagesIds, itemId.Id)
+		case steam.ItemBundle:
+			bundlesIds = append(bundlesIds, itemId.Id)
+		}
+	}
+
+	return appsIds, packagesIds, bundlesIds
+}
+
+// Store items of one country, priced once for every user living there
+type steamCatalog struct {
+	editions map[steam.ItemId]steamEdition
+	// Every app that came back, priced or not
+	appNames map[uint64]string
+}
+
+// Editions skip apps that are not sold as the game
+func obtainSteamCatalog(ctx context.Context, itemIds []steam.ItemId, countryCode string) (steamCatalog, error) {
+	appsIds, packagesIds, bundlesIds := splitSteamItemIds(itemIds)
+
+	catalog := steamCatalog{
+		editions: make(map[steam.ItemId]steamEdition),
+		appNames: make(map[uint64]string),
+	}
+
+	if len(appsIds) > 0 {
+		appsDetails, err := obtainSteamAppsDetails(ctx, appsIds, countryCode)
+		if err != nil {
+			return catalog, err
+		}
+
+		for _, appDetails := range appsDetails {
+			catalog.appNames[appDetails.SteamAppId] = appDetails.Name
+			if !appDetails.IsEdition() || appDetails.PriceOverview == nil {
+				continue
+			}
+
+			catalog.addEdition(steamEdition{
+				item:            steam.ItemId{Kind: steam.ItemApp, Id: appDetails.SteamAppId},
+				name:            appDetails.Name,
+				image:           appDetails.HeaderImage,
+				description:     appDetails.ShortDescription,
+				discountPercent: appDetails.PriceOverview.DiscountPercent,
+				initial:         types.NewMoney(int64(appDetails.PriceOverview.Initial), appDetails.PriceOverview.Currency),
+				final:           types.NewMoney(int64(appDetails.PriceOverview.Final), appDetails.PriceOverview.Currency),
+			})
+		}
+	}
+
+	if len(packagesIds) > 0 {
+		packagesDetails, packagesErrors := upstreams.Steam.RequestPackageDetails(ctx, packagesIds, countryCode)
+		if err := checkSteamItemsErrors(steam.ItemSub, len(packagesDetails), packagesErrors); err != nil {
+			return catalog, errors.Join(errors.New("could not get packages details from steam:"), err)
+		}
+
+		for _, packageDetails := range packagesDetails {
+			catalog.addEdition(steamEdition{
+				item:            steam.ItemId{Kind: steam.ItemSub, Id: packageDetails.PackageId},
+				name:            packageDetails.Name,
+				image:           packageDetails.HeaderImage,
+				discountPercent: packageDetails.Price.DiscountPercent,
+				initial:         types.NewMoney(int64(packageDetails.Price.Initial), packageDetails.Price.Currency),
+				final:           types.NewMoney(int64(packageDetails.Price.Final), packageDetails.Price.Currency),
+			})
+		}
+	}
+
+	if len(bundlesIds) > 0 {
+		bundlesDetails, err := upstreams.Steam.RequestBundleDetails(ctx, bundlesIds, countryCode)
+		if err != nil {
+			return catalog, errors.Join(errors.New("could not get bundles details from steam:"), err)
+		}
+
+		// Bundles come without currency, so assume the store currency of the region
+		bundleCurrency := configs.GetSteamCurrency(countryCode)
+		for _, bundleDetails := range bundlesDetails {
+			catalog.addEdition(steamEdition{
+				item:            steam.ItemId{Kind: steam.ItemBundle, Id: bundleDetails.BundleId},
+				name:            bundleDetails.Name,
+				image:           bundleDetails.HeaderImage,
+				discountPercent: bundleDetails.DiscountPercent,
+				initial:         types.NewMoney(int64(bundleDetails.InitialPrice), bundleCurrency),
+				final:           types.NewMoney(int64(bundleDetails.FinalPrice), bundleCurrency),
+			})
+		}
+	}
+
+	return catalog, nil
+}
+
+func (c steamCatalog) addEdition(edition steamEdition) {
+	c.editions[edition.item] = edition
+}
+
+// Names of the user's apps are put into names
+func (c steamCatalog) getEditions(itemIds []steam.ItemId, names map[string]string) []steamEdition {
+	var editions []steamEdition
+	seenItemIds := make(map[steam.ItemId]bool)
+	for _, itemId := range itemIds {
+		if seenItemIds[itemId] {
+			continue
+		}
+		seenItemIds[itemId] = true
+
+		if name, isExists := c.appNames[itemId.Id]; isExists && itemId.Kind == steam.ItemApp {
+			names[strconv.FormatUint(itemId.Id, 10)] = name
+		}
+
+		if edition, isExists := c.editions[itemId]; isExists {
+			editions = append(editions, edition)
+		}
+	}
+
+	return editions
+}
+
+// Discounted editions are grouped under their igdb game and only the cheapest one is reported.
+// Steam items without a game stand on their own
+func pickCheapestEditions(editions []steamEdition, slugsBySteamItemId map[steam.ItemId]string, names map[string]string, coversBySlug map[string]string) []models.Sale {
+	var keys []string
+	cheapestEditions := make(map[string]steamEdition)
+	for _, edition := range editions {
+		if edition.discountPercent <= 0 {
+			continue
+		}
+
+		key := slugsBySteamItemId[edition.item]
+		if key == "" {
+			key = edition.item.Uid()
+		}
+
+		cheapestEdition, isExists := cheapestEditions[key]
+		if !isExists {
+			keys = append(keys, key)
+		} else if cheapestEdition.final.Hundredths <= edition.final.Hundredths {
+			continue
+		}
+
+		cheapestEditions[key] = edition
+	}
+
+	var sales []models.Sale
+	for _, key := range keys {
+		edition := cheapestEditions[key]
+		slug := slugsBySteamItemId[edition.item]
+
+		sale := models.Sale{
+			Slug:            slug,
+			Name:            edition.name,
+			Url:             edition.item.GetStoreUrl(),
+			Image:           edition.image,
+			Description:     edition.description,
+			DiscountPercent: edition.discountPercent,
+			InitialPrice:    edition.initial,
+			FinalPrice:      edition.final,
+		}
+
+		if edition.item.Kind == steam.ItemApp {
+			sale.SteamAppId = edition.item.Id
+		}
+
+		// Same rule as steam.AvailabilityGiveaway, but packages and bundles can be given away too
+		if edition.final.IsZero() && edition.discountPercent == 100 {
+			sale.Giveaway = true
+		}
+
+		// Game name goes first, the edition tells what exactly is on sale
+		if name, isExists := names[slug]; slug != "" && isExists && name != edition.name {
+			sale.Name = name
+			sale.Edition = edition.name
+		}
+
+		// Prefer igdb cover, steam header is there for games without igdb record
+		if cover := coversBySlug[slug]; cover != "" {
+			sale.Image = cover
+		}
+
+		sales = append(sales, sale)
+	}
+
+	return sales
+}
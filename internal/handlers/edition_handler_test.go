@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+func newTestEdition(kind steam.ItemKind, id uint64, name string, discountPercent int, initial int64, final int64) steamEdition {
+	return steamEdition{
+		item:            steam.ItemId{Kind: kind, Id: id},
+		name:            name,
+		image:           "header.jpg",
+		discountPercent: discountPercent,
+		initial:         types.NewMoney(initial, "USD"),
+		final:           types.NewMoney(final, "USD"),
+	}
+}
+
+func TestPickCheapestEditions(t *testing.T) {
+	standard := newTestEdition(steam.ItemApp, 292030, "The Witcher 3: Wild Hunt", 50, 3999, 1999)
+	complete := newTestEdition(steam.ItemApp, 499450, "The Witcher 3: Wild Hunt - Complete Edition", 80, 4999, 999)
+	bundle := newTestEdition(steam.ItemBundle, 1162, "Witcher Bundle", 60, 5999, 2399)
+	fullPrice := newTestEdition(steam.ItemApp, 620, "Portal 2", 0, 999, 999)
+	giveaway := newTestEdition(steam.ItemSub, 469, "Free Weekend Pack", 100, 1999, 0)
+	loose := newTestEdition(steam.ItemApp, 367520, "Hollow Knight", 50, 1499, 749)
+
+	slugsBySteamItemId := map[steam.ItemId]string{
+		standard.item:  "the-witcher-3-wild-hunt",
+		complete.item:  "the-witcher-3-wild-hunt",
+		bundle.item:    "the-witcher-3-wild-hunt",
+		fullPrice.item: "portal-2",
+	}
+	names := map[string]string{"the-witcher-3-wild-hunt": "The Witcher 3: Wild Hunt", "portal-2": "Portal 2"}
+	coversBySlug := map[string]string{"the-witcher-3-wild-hunt": "cover.jpg"}
+
+	tests := []struct {
+		name     string
+		editions []steamEdition
+		want     []models.Sale
+	}{
+		{
+			name:     "cheapest edition stands for the game under its igdb name",
+			editions: []steamEdition{standard, bundle, complete},
+			want: []models.Sale{{
+				Slug: "the-witcher-3-wild-hunt", SteamAppId: 499450, Name: "The Witcher 3: Wild Hunt", Edition: complete.name,
+				Url: complete.item.GetStoreUrl(), Image: "cover.jpg", DiscountPercent: 80, InitialPrice: complete.initial, FinalPrice: complete.final,
+			}},
+		},
+		{
+			name:     "game itself keeps its name",
+			editions: []steamEdition{standard},
+			want: []models.Sale{{
+				Slug: "the-witcher-3-wild-hunt", SteamAppId: 292030, Name: standard.name,
+				Url: standard.item.GetStoreUrl(), Image: "cover.jpg", DiscountPercent: 50, InitialPrice: standard.initial, FinalPrice: standard.final,
+			}},
+		},
+		{
+			name:     "full price editions are no deal",
+			editions: []steamEdition{fullPrice},
+			want:     nil,
+		},
+		{
+			name:     "items without a game stand on their own",
+			editions: []steamEdition{giveaway, loose},
+			want: []models.Sale{
+				{Name: giveaway.name, Url: giveaway.item.GetStoreUrl(), Image: "header.jpg", DiscountPercent: 100, InitialPrice: giveaway.initial, FinalPrice: giveaway.final, Giveaway: true},
+				{SteamAppId: 367520, Name: loose.name, Url: loose.item.GetStoreUrl(), Image: "header.jpg", DiscountPercent: 50, InitialPrice: loose.initial, FinalPrice: loose.final},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := pickCheapestEditions(test.editions, slugsBySteamItemId, names, coversBySlug)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %d sales, want %d: %+v", len(got), len(test.want), got)
+			}
+
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("sale %d: got %+v, want %+v", i, got[i], test.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/mymmrac/telego/telegoutil"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+type exportRow struct {
+	Slug       string `json:"slug"`
+	Name       string `json:"name"`
+	IgdbId     uint64 `json:"igdb_id,omitempty"`
+	SteamAppId uint64 `json:"steam_app_id,omitempty"`
+	// Packages and bundles have no app id: "sub/<id>" or "bundle/<id>"
+	SteamItem string `json:"steam_item,omitempty"`
+	// Json keeps amounts in hundredths the way types.Money does, csv has them as decimals next to the currency
+	FinalPrice      *types.Money `json:"final_price,omitempty"`
+	InitialPrice    *types.Money `json:"initial_price,omitempty"`
+	DiscountPercent int          `json:"discount_percent"`
+	Availability    string       `json:"availability"`
+	Url             string       `json:"url"`
+}
+
+func ExportHandler(ctx *telegohandler.Context, update telego.Update) error {
+	format := getCommandArgument(update.Message.Text)
+	if format == "" {
+		format = "csv"
+	}
+
+	if format != "csv" && format != "json" {
+		message := "Boss, I can export your games either as csv or json using the /export <csv|json> command."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /export command: unknown format:"), err)
+		}
+
+		return nil
+	}
+
+	rows, err := collectExportRows(ctx, update.Message.Chat.ID)
+	if err != nil {
+		message := "Couldn't collect your games for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /export command: could not collect rows:"), err)
+		}
+
+		return nil
+	}
+
+	if len(rows) == 0 {
+		message := "Nothing to export yet, boss. I fill this in after checking your wishlist."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /export command: nothing to export:"), err)
+		}
+
+		return nil
+	}
+
+	var data []byte
+	if format == "json" {
+		data, err = json.MarshalIndent(rows, "", "  ")
+	} else {
+		data, err = formatExportCsv(rows)
+	}
+
+	if err != nil {
+		return errors.Join(errors.New("handler: could not handle /export command: could not format rows:"), err)
+	}
+
+	if _, err := ctx.Bot().SendDocument(ctx, telegoutil.Document(
+		telegoutil.ID(update.Message.Chat.ID),
+		telegoutil.FileFromBytes(data, "wishlist."+format),
+	)); err != nil {
+		return errors.Join(errors.New("handler: could not handle /export command: could not send document:"), err)
+	}
+
+	return nil
+}
+
+// Games are resolved the way runs do it: slug matches, then store overrides, then steam items.
+// Upstream apis are only asked about what is not cached yet
+func collectExportRows(ctx context.Context, userId int64) ([]exportRow, error) {
+	wishlist, err := runStore.GetWishlist(userId)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not get wishlist:"), err)
+	}
+
+	if wishlist == nil {
+		return []exportRow{}, nil
+	}
+
+	// Prices are cached per country
+	userSettings, err := repos.GetUserSettingsByUserId(userId)
+	if err != nil {
+		return nil, errors.Join(errors.New("could not get user settings:"), err)
+	}
+
+	countryCode := ""
+	if userSettings != nil {
+		countryCode = strings.ToLower(userSettings.CountryCode)
+	}
+
+	var igdbGames []igdb.Game
+	if len(wishlist.SlugList) > 0 {
+		// Stored wishlists keep no titles, slugs stand in for them when a new match is searched
+		catalog, err := obtainIgdbCatalog(ctx, wishlist.SlugList, nil)
+		if err != nil {
+			return nil, errors.Join(errors.New("could not obtain igdb games:"), err)
+		}
+
+		igdbGames, _ = catalog.getGames(userId, wishlist.SlugList)
+
+		names := make(map[string]string)
+		for _, igdbGame := range igdbGames {
+			names[igdbGame.Slug] = igdbGame.Name
+		}
+
+		storeOverrides, err := runStore.GetStoreOverrides(userId, wishlist.SlugList)
+		if err != nil {
+			return nil, errors.Join(errors.New("could not get store overrides:"), err)
+		}
+
+		igdbGames = applyStoreOverrides(igdbGames, storeOverrides, names)
+	}
+
+	var steamItemIds []steam.ItemId
+	for _, igdbGame := range igdbGames {
+		steamItemIds = append(steamItemIds, getSteamItemIds(igdbGame)...)
+	}
+
+	for _, steamAppId := range wishlist.SteamAppIds {
+		steamItemIds = append(steamItemIds, steam.ItemId{Kind: steam.ItemApp, Id: steamAppId})
+	}
+
+	prices, err := obtainExportPrices(ctx, steamItemIds, countryCode)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []exportRow
+	exportedItemIds := make(map[steam.ItemId]bool)
+	for _, igdbGame := range igdbGames {
+		gameItemIds := getSteamItemIds(igdbGame)
+		if len(gameItemIds) == 0 {
+			rows = append(rows, exportRow{Slug: igdbGame.Slug, Name: igdbGame.Name, IgdbId: igdbGame.Id})
+			continue
+		}
+
+		for _, steamItemId := range gameItemIds {
+			row := prices.newExportRow(steamItemId)
+			row.Slug = igdbGame.Slug
+			row.IgdbId = igdbGame.Id
+			if row.Name == "" {
+				row.Name = igdbGame.Name
+			}
+
+			rows = append(rows, row)
+			exportedItemIds[steamItemId] = true
+		}
+	}
+
+	for _, steamAppId := range wishlist.SteamAppIds {
+		steamItemId := steam.ItemId{Kind: steam.ItemApp, Id: steamAppId}
+		if !exportedItemIds[steamItemId] {
+			rows = append(rows, prices.newExportRow(steamItemId))
+			exportedItemIds[steamItemId] = true
+		}
+	}
+
+	return rows, nil
+}
+
+// Apps keep their whole details for availability, packages and bundles only come priced
+type exportPrices struct {
+	appsDetails map[uint64]steam.AppDetails
+	editions    map[steam.ItemId]steamEdition
+}
+
+func obtainExportPrices(ctx context.Context, steamItemIds []steam.ItemId, countryCode string) (exportPrices, error) {
+	prices := exportPrices{
+		appsDetails: make(map[uint64]steam.AppDetails),
+		editions:    make(map[steam.ItemId]steamEdition),
+	}
+
+	var editionItemIds []steam.ItemId
+	var steamAppsIds []uint64
+	for _, steamItemId := range steamItemIds {
+		if steamItemId.Kind == steam.ItemApp {
+			steamAppsIds = append(steamAppsIds, steamItemId.Id)
+		} else {
+			editionItemIds = append(editionItemIds, steamItemId)
+		}
+	}
+
+	if len(steamAppsIds) > 0 {
+		steamAppsDetails, err := obtainSteamAppsDetails(ctx, uniqueSteamAppsIds(steamAppsIds), countryCode)
+		if err != nil {
+			return prices, errors.Join(errors.New("could not obtain steam apps details:"), err)
+		}
+
+		for _, steamAppDetails := range steamAppsDetails {
+			prices.appsDetails[steamAppDetails.SteamAppId] = steamAppDetails
+		}
+	}
+
+	if len(editionItemIds) > 0 {
+		catalog, err := obtainSteamCatalog(ctx, editionItemIds, countryCode)
+		if err != nil {
+			return prices, errors.Join(errors.New("could not obtain steam packages and bundles:"), err)
+		}
+
+		prices.editions = catalog.editions
+	}
+
+	return prices, nil
+}
+
+func (p exportPrices) newExportRow(steamItemId steam.ItemId) exportRow {
+	row := exportRow{
+		SteamItem: steamItemId.Uid(),
+		Url:       steamItemId.GetStoreUrl(),
+	}
+
+	if steamItemId.Kind != steam.ItemApp {
+		if edition, isExists := p.editions[steamItemId]; isExists {
+			row.Name = edition.name
+			row.Availability = string(steam.AvailabilityPriced)
+			if edition.final.IsZero() && edition.discountPercent == 100 {
+				row.Availability = string(steam.AvailabilityGiveaway)
+			}
+			row.FinalPrice = &edition.final
+			row.InitialPrice = &edition.initial
+			row.DiscountPercent = edition.discountPercent
+		}
+
+		return row
+	}
+
+	row.SteamAppId = steamItemId.Id
+	if steamAppDetails, isExists := p.appsDetails[steamItemId.Id]; isExists {
+		row.Name = steamAppDetails.Name
+		row.Availability = string(steamAppDetails.GetAvailability())
+		if steamAppDetails.PriceOverview != nil {
+			finalPrice := types.NewMoney(int64(steamAppDetails.PriceOverview.Final), steamAppDetails.PriceOverview.Currency)
+			initialPrice := types.NewMoney(int64(steamAppDetails.PriceOverview.Initial), steamAppDetails.PriceOverview.Currency)
+			row.FinalPrice = &finalPrice
+			row.InitialPrice = &initialPrice
+			row.DiscountPercent = steamAppDetails.PriceOverview.DiscountPercent
+		}
+	}
+
+	return row
+}
+
+func formatExportCsv(rows []exportRow) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	if err := writer.Write([]string{"slug", "name", "igdb_id", "steam_app_id", "steam_item", "currency", "final_price", "initial_price", "discount_percent", "availability", "url"}); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		record := []string{
+			row.Slug,
+			row.Name,
+			formatOptionalId(row.IgdbId),
+			formatOptionalId(row.SteamAppId),
+			row.SteamItem,
+			formatExportCurrency(row.FinalPrice),
+			formatExportAmount(row.FinalPrice),
+			formatExportAmount(row.InitialPrice),
+			strconv.Itoa(row.DiscountPercent),
+			row.Availability,
+			row.Url,
+		}
+
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func formatExportCurrency(price *types.Money) string {
+	if price == nil {
+		return ""
+	}
+
+	return price.Currency
+}
+
+func formatExportAmount(price *types.Money) string {
+	if price == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(price.Float(), 'f', 2, 64)
+}
+
+func formatOptionalId(id uint64) string {
+	if id == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(id, 10)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+)
+
+const filterUsage = "Boss, set your filters using these commands:\n" +
+	"/filter platform <name> to only track games on this platform, e.g. pc\n" +
+	"/filter exclude <genre> to skip games of this genre or theme, e.g. horror\n" +
+	"/filter multiplayer-only <hide|show> to skip games without single player\n" +
+	"/filter rating <percent|off> to skip deals with fewer positive Steam reviews, e.g. 80\n" +
+	"/filter clear to drop all filters"
+
+func FilterHandler(ctx *telegohandler.Context, update telego.Update) error {
+	settings, err := repos.GetUserSettingsByUserId(update.Message.Chat.ID)
+	if err != nil {
+		message := "Couldn't get your filters for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /filter command: could not get settings:"), err)
+		}
+
+		return nil
+	}
+
+	var filters models.GameFilters
+	if settings != nil {
+		filters = settings.Filters
+	}
+
+	args := strings.SplitN(getCommandArgument(update.Message.Text), " ", 2)
+	kind := strings.ToLower(args[0])
+	value := ""
+	if len(args) == 2 {
+		value = strings.ToLower(strings.TrimSpace(args[1]))
+	}
+
+	switch {
+	case kind == "":
+		if err := sendMessage(ctx, update, formatGameFilters(filters)+"\n\n"+filterUsage); err != nil {
+			return errors.Join(errors.New("handler: could not handle /filter command: send filters:"), err)
+		}
+
+		return nil
+	case kind == "platform" && value != "":
+		filters.Platforms = appendUnique(filters.Platforms, value)
+	case kind == "exclude" && value != "":
+		filters.ExcludedGenres = appendUnique(filters.ExcludedGenres, value)
+	case kind == "multiplayer-only" && (value == "hide" || value == "show"):
+		filters.ExcludeMultiplayerOnly = value == "hide"
+	case kind == "rating" && value == "off":
+		filters.MinReviewPercent = 0
+	case kind == "rating" && isReviewPercent(value):
+		filters.MinReviewPercent, _ = strconv.Atoi(strings.TrimSuffix(value, "%"))
+	case kind == "clear":
+		filters = models.GameFilters{}
+	default:
+		if err := sendMessage(ctx, update, filterUsage); err != nil {
+			return errors.Join(errors.New("handler: could not handle /filter command: unknown arguments:"), err)
+		}
+
+		return nil
+	}
+
+	if err := repos.UpsertFiltersSetting(update.Message.Chat.ID, filters); err != nil {
+		message := "Couldn't update your filters for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /filter command: could not upsert filters:"), err)
+		}
+
+		return nil
+	}
+
+	if err := sendMessage(ctx, update, "Got your filters updated, boss.\n"+formatGameFilters(filters)); err != nil {
+		return errors.Join(errors.New("handler: could not handle /filter command: send confirmation message:"), err)
+	}
+
+	return nil
+}
+
+// Runs before pricing, so filtered out games never reach steam
+func applyGameFilters(igdbGames []igdb.Game, filters models.GameFilters) []igdb.Game {
+	if filters.IsEmpty() {
+		return igdbGames
+	}
+
+	var filteredGames []igdb.Game
+	for _, igdbGame := range igdbGames {
+		if isGameAllowed(igdbGame, filters) {
+			filteredGames = append(filteredGames, igdbGame)
+		}
+	}
+
+	return filteredGames
+}
+
+func isGameAllowed(igdbGame igdb.Game, filters models.GameFilters) bool {
+	if filters.ExcludeMultiplayerOnly && igdbGame.IsMultiplayerOnly() {
+		return false
+	}
+
+	for _, excludedGenre := range filters.ExcludedGenres {
+		if igdbGame.HasGenreOrTheme(excludedGenre) {
+			return false
+		}
+	}
+
+	// Games without platform data are kept, igdb is not always complete
+	if len(filters.Platforms) == 0 || len(igdbGame.Platforms) == 0 {
+		return true
+	}
+
+	for _, wantedPlatform := range filters.Platforms {
+		for _, platform := range igdbGame.Platforms {
+			if strings.EqualFold(platform.Abbreviation, wantedPlatform) || strings.Contains(strings.ToLower(platform.Name), wantedPlatform) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func formatGameFilters(filters models.GameFilters) string {
+	if filters.IsEmpty() {
+		return "No filters set."
+	}
+
+	var lines []string
+	if len(filters.Platforms) > 0 {
+		lines = append(lines, fmt.Sprintf("Platforms: %s", strings.Join(filters.Platforms, ", ")))
+	}
+
+	if len(filters.ExcludedGenres) > 0 {
+		lines = append(lines, fmt.Sprintf("Excluded genres: %s", strings.Join(filters.ExcludedGenres, ", ")))
+	}
+
+	if filters.ExcludeMultiplayerOnly {
+		lines = append(lines, "Multiplayer-only games are hidden")
+	}
+
+	if filters.MinReviewPercent > 0 {
+		lines = append(lines, fmt.Sprintf("Deals need at least %d%% positive reviews", filters.MinReviewPercent))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func isReviewPercent(value string) bool {
+	percent, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	return err == nil && percent > 0 && percent <= 100
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existingValue := range values {
+		if existingValue == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
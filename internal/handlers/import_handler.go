@@ -0,0 +1,263 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/mymmrac/telego/telegoutil"
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/parsers"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+	"github.com/theverysameliquidsnake/sales-bot/internal/requests"
+)
+
+const (
+	maxImportFileSize    = 1 << 20
+	maxImportReportLines = 20
+)
+
+type watchlistImportResult struct {
+	matched   []models.WatchlistEntry
+	ambiguous []string
+	unmatched []string
+}
+
+// Only csv and json files are read, anything else sent to the bot is left alone
+func IsImportDocument(document telego.Document) bool {
+	if document.FileSize > maxImportFileSize {
+		return false
+	}
+
+	switch strings.ToLower(document.MimeType) {
+	case "text/csv", "text/plain", "application/json":
+		return true
+	}
+
+	fileName := strings.ToLower(document.FileName)
+	return strings.HasSuffix(fileName, ".csv") || strings.HasSuffix(fileName, ".txt") || strings.HasSuffix(fileName, ".json")
+}
+
+func ImportHandler(ctx *telegohandler.Context, update telego.Update) error {
+	document := update.Message.Document
+	file, err := ctx.Bot().GetFile(ctx, &telego.GetFileParams{FileID: document.FileID})
+	if err != nil {
+		return errors.Join(errors.New("handler: could not handle import: could not get file:"), err)
+	}
+
+	data, err := telegoutil.DownloadFile(ctx.Bot().FileDownloadURL(file.FilePath))
+	if err != nil {
+		return errors.Join(errors.New("handler: could not handle import: could not download file:"), err)
+	}
+
+	rows, err := parsers.ParseWatchlistImport(document.FileName, data)
+	if err != nil {
+		message := "Couldn't read this file, boss. Send me a CSV with title, slug or steam_app_id columns, or a JSON array of them."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle import: could not parse file:"), err)
+		}
+
+		return nil
+	}
+
+	result, err := resolveWatchlistImport(ctx, update.Message.Chat.ID, rows)
+	if err != nil {
+		message := "Couldn't look up these games for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle import: could not resolve rows:"), err)
+		}
+
+		return nil
+	}
+
+	for _, entry := range result.matched {
+		if err := repos.UpsertWatchlistEntry(entry); err != nil {
+			return errors.Join(errors.New("handler: could not handle import: could not upsert entry:"), err)
+		}
+	}
+
+	if err := sendMessage(ctx, update, formatWatchlistImportResult(result)); err != nil {
+		return errors.Join(errors.New("handler: could not handle import: send report message:"), err)
+	}
+
+	return nil
+}
+
+func resolveWatchlistImport(ctx context.Context, userId int64, rows []models.WatchlistImportRow) (watchlistImportResult, error) {
+	var result watchlistImportResult
+
+	var slugs []string
+	var steamAppIds []uint64
+	for _, row := range rows {
+		if row.Slug != "" {
+			slugs = append(slugs, row.Slug)
+		} else if row.SteamAppId != 0 {
+			steamAppIds = append(steamAppIds, row.SteamAppId)
+		}
+	}
+
+	gamesBySlug := make(map[string]igdb.Game)
+	if len(slugs) > 0 {
+		games, err := upstreams.Igdb.RequestGamesBySlugs(ctx, slugs)
+		if err != nil {
+			return result, errors.Join(errors.New("could not request igdb games by slugs:"), err)
+		}
+
+		for _, game := range games {
+			gamesBySlug[game.Slug] = game
+		}
+	}
+
+	gamesBySteamAppId := make(map[uint64]igdb.Game)
+	if len(steamAppIds) > 0 {
+		games, err := upstreams.Igdb.RequestGamesBySteamAppIds(ctx, steamAppIds)
+		if err != nil {
+			return result, errors.Join(errors.New("could not request igdb games by steam apps ids:"), err)
+		}
+
+		for _, game := range games {
+			for _, steamAppId := range getSteamAppsIds(game) {
+				gamesBySteamAppId[steamAppId] = game
+			}
+		}
+	}
+
+	// Steam knows games igdb does not, those are kept only if Steam confirms the app
+	var unknownSteamAppIds []uint64
+	for _, steamAppId := range steamAppIds {
+		if _, isExists := gamesBySteamAppId[steamAppId]; !isExists {
+			unknownSteamAppIds = append(unknownSteamAppIds, steamAppId)
+		}
+	}
+
+	steamNames := make(map[uint64]string)
+	if len(unknownSteamAppIds) > 0 {
+		appsDetails, appsErrors := upstreams.Steam.RequestLocalizedAppDetails(ctx, uniqueSteamAppsIds(unknownSteamAppIds), configs.GetSteamLanguage(configs.DefaultLanguage))
+		for steamAppId, err := range appsErrors {
+			if !errors.Is(err, requests.ErrSteamAppUnavailable) {
+				log.Printf("handler: skipping imported steam app %d: %v", steamAppId, err)
+			}
+		}
+
+		for _, appDetails := range appsDetails {
+			steamNames[appDetails.SteamAppId] = appDetails.Name
+		}
+	}
+
+	for _, row := range rows {
+		switch {
+		case row.Slug != "":
+			game, isExists := gamesBySlug[row.Slug]
+			if !isExists {
+				result.unmatched = append(result.unmatched, fmt.Sprintf("%d: %s", row.Line, row.Slug))
+				continue
+			}
+
+			result.matched = append(result.matched, newWatchlistEntry(userId, game))
+		case row.SteamAppId != 0:
+			if game, isExists := gamesBySteamAppId[row.SteamAppId]; isExists {
+				result.matched = append(result.matched, newWatchlistEntry(userId, game))
+				continue
+			}
+
+			// Priced directly, there is no igdb record to go through
+			name, isExists := steamNames[row.SteamAppId]
+			if !isExists {
+				result.unmatched = append(result.unmatched, fmt.Sprintf("%d: steam app %d", row.Line, row.SteamAppId))
+				continue
+			}
+
+			result.matched = append(result.matched, models.WatchlistEntry{
+				UserId:     userId,
+				SteamAppId: row.SteamAppId,
+				Name:       name,
+			})
+		default:
+			games, err := upstreams.Igdb.SearchGames(ctx, row.Title, igdbSearchLimit)
+			if err != nil {
+				return result, errors.Join(fmt.Errorf("could not search igdb games: %s", row.Title), err)
+			}
+
+			game, candidates := pickImportCandidate(row.Title, games)
+			switch {
+			case game != nil:
+				result.matched = append(result.matched, newWatchlistEntry(userId, *game))
+			case len(candidates) > 0:
+				result.ambiguous = append(result.ambiguous, fmt.Sprintf("%d: %s (%s)", row.Line, row.Title, strings.Join(candidates, ", ")))
+			default:
+				result.unmatched = append(result.unmatched, fmt.Sprintf("%d: %s", row.Line, row.Title))
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// Picks the only exact name match or a lone result close enough to the title, otherwise returns
+// candidate names for the user to choose from
+func pickImportCandidate(title string, games []igdb.Game) (*igdb.Game, []string) {
+	if len(games) == 1 {
+		score := scoreMatchCandidate(title, games[0])
+		switch {
+		case score >= confidentMatchScore:
+			return &games[0], nil
+		case score >= minCandidateScore:
+			return nil, []string{games[0].Name}
+		default:
+			return nil, nil
+		}
+	}
+
+	var exactMatches []igdb.Game
+	var candidates []string
+	for _, game := range games {
+		if strings.EqualFold(game.Name, title) {
+			exactMatches = append(exactMatches, game)
+		}
+
+		candidates = append(candidates, game.Name)
+	}
+
+	if len(exactMatches) == 1 {
+		return &exactMatches[0], nil
+	}
+
+	return nil, candidates
+}
+
+func newWatchlistEntry(userId int64, game igdb.Game) models.WatchlistEntry {
+	return models.WatchlistEntry{
+		UserId: userId,
+		IgdbId: game.Id,
+		Slug:   game.Slug,
+		Name:   game.Name,
+	}
+}
+
+func formatWatchlistImportResult(result watchlistImportResult) string {
+	message := fmt.Sprintf("Imported %d games to your watchlist, boss.", len(result.matched))
+
+	if len(result.ambiguous) > 0 {
+		message += fmt.Sprintf("\n\nNot sure which game you meant in %d rows, add them with /add:\n%s", len(result.ambiguous), formatImportReportLines(result.ambiguous))
+	}
+
+	if len(result.unmatched) > 0 {
+		message += fmt.Sprintf("\n\nCouldn't find anything for %d rows:\n%s", len(result.unmatched), formatImportReportLines(result.unmatched))
+	}
+
+	return message
+}
+
+func formatImportReportLines(lines []string) string {
+	if len(lines) <= maxImportReportLines {
+		return strings.Join(lines, "\n")
+	}
+
+	return strings.Join(lines[:maxImportReportLines], "\n") + fmt.Sprintf("\n...and %d more", len(lines)-maxImportReportLines)
+}
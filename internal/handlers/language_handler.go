@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+	"github.com/theverysameliquidsnake/sales-bot/internal/requests"
+)
+
+// Store names and descriptions rarely change, localized ones are checked again once a month
+const steamLocalizedNameTtl = 30 * 24 * time.Hour
+
+func LanguageHandler(ctx *telegohandler.Context, update telego.Update) error {
+	language := strings.ToLower(getCommandArgument(update.Message.Text))
+	if language == "" {
+		message := "Boss, pick a language for Steam names and descriptions using the /language <code> command, like /language de. Only game names, descriptions and price formats change, the rest of my messages stay in English. Use /language reset to go back to English."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /language command: not enough arguments:"), err)
+		}
+
+		return nil
+	}
+
+	if language == "reset" {
+		language = ""
+	} else if !configs.IsSupportedLanguage(language) {
+		message := "Steam does not speak this language. Try another code, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /language command: unknown language:"), err)
+		}
+
+		return nil
+	}
+
+	if err := repos.UpsertLanguageSetting(update.Message.Chat.ID, language); err != nil {
+		message := "Couldn't update your language for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /language command: could not upsert setting:"), err)
+		}
+
+		return nil
+	}
+
+	message := "Got it, boss. Steam names and descriptions are back to English."
+	if language != "" {
+		message = "Got it, boss. I will show Steam names and descriptions in your language where Steam has them."
+	}
+
+	if err := sendMessage(ctx, update, message); err != nil {
+		return errors.Join(errors.New("handler: could not handle /language command: send confirmation message:"), err)
+	}
+
+	return nil
+}
+
+// Names Steam fails to give are skipped, English ones are still there to show
+func obtainSteamLocalizedNames(ctx context.Context, steamAppsIds []uint64, language string, now time.Time) (map[uint64]steam.LocalizedName, error) {
+	cachedNames, err := runStore.GetSteamLocalizedNames(steamAppsIds, language, now.Add(-steamLocalizedNameTtl))
+	if err != nil {
+		return nil, errors.Join(errors.New("could not get cached localized names:"), err)
+	}
+
+	localizedNames := make(map[uint64]steam.LocalizedName)
+	for _, localizedName := range cachedNames {
+		localizedNames[localizedName.SteamAppId] = localizedName
+	}
+
+	var idsToRequest []uint64
+	for _, steamAppId := range steamAppsIds {
+		if _, isExists := localizedNames[steamAppId]; !isExists {
+			idsToRequest = append(idsToRequest, steamAppId)
+		}
+	}
+
+	if len(idsToRequest) == 0 {
+		return localizedNames, nil
+	}
+
+	appsDetails, appsErrors := upstreams.Steam.RequestLocalizedAppDetails(ctx, idsToRequest, language)
+	for steamAppId, err := range appsErrors {
+		if !errors.Is(err, requests.ErrSteamAppUnavailable) && ctx.Err() == nil {
+			log.Printf("handler: skipping %s name of steam app %d: %v", language, steamAppId, err)
+		}
+	}
+
+	for _, appDetails := range appsDetails {
+		localizedName := steam.LocalizedName{
+			SteamAppId:  appDetails.SteamAppId,
+			Language:    language,
+			Name:        appDetails.Name,
+			Description: appDetails.ShortDescription,
+			CheckedAt:   now,
+		}
+
+		if err = runStore.UpsertSteamLocalizedName(localizedName); err != nil {
+			return nil, errors.Join(errors.New("could not cache localized name:"), err)
+		}
+
+		localizedNames[localizedName.SteamAppId] = localizedName
+	}
+
+	return localizedNames, nil
+}
+
+// Steam apps on sale and among wishlist changes, the ones a notification shows names of
+func getLocalizableSteamAppsIds(report wishlistReport) []uint64 {
+	steamAppsIds := getSalesSteamAppsIds(report.sales)
+	for _, keys := range [][]string{report.changes.added, report.changes.removed} {
+		for _, key := range keys {
+			if steamAppId, err := strconv.ParseUint(key, 10, 64); err == nil {
+				steamAppsIds = append(steamAppsIds, steamAppId)
+			}
+		}
+	}
+
+	return steamAppsIds
+}
+
+// Sales keep the igdb name of the game when an edition is on sale, the edition name is localized instead
+func localizeReport(report *wishlistReport, localizedNames map[uint64]steam.LocalizedName) {
+	for i, sale := range report.sales {
+		localizedName, isExists := localizedNames[sale.SteamAppId]
+		if !isExists {
+			continue
+		}
+
+		if localizedName.Description != "" {
+			report.sales[i].Description = localizedName.Description
+		}
+
+		if localizedName.Name == "" {
+			continue
+		}
+
+		if sale.Edition != "" {
+			report.sales[i].Edition = localizedName.Name
+		} else {
+			report.sales[i].Name = localizedName.Name
+		}
+	}
+
+	for _, keys := range [][]string{report.changes.added, report.changes.removed} {
+		for _, key := range keys {
+			steamAppId, err := strconv.ParseUint(key, 10, 64)
+			if err != nil {
+				continue
+			}
+
+			if localizedName, isExists := localizedNames[steamAppId]; isExists && localizedName.Name != "" {
+				report.names[key] = localizedName.Name
+			}
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+)
+
+const mapUsage = "Boss, tell me which Steam page belongs to a game using the /map <game> <steam url> command. " +
+	"The game is a Backloggd link, slug or title, the url may point to an app, package or bundle. Use none instead of the url to stop checking Steam for it, or reset to drop your mapping."
+
+var (
+	backloggdGameUrlRegexp = regexp.MustCompile(`backloggd\.com/games/([^/?#]+)`)
+	steamItemUrlRegexp     = regexp.MustCompile(`store\.steampowered\.com/(app|sub|bundle)/(\d+)`)
+	slugSeparatorRegexp    = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+func MapHandler(ctx *telegohandler.Context, update telego.Update) error {
+	if err := handleMapCommand(ctx, update, update.Message.Chat.ID); err != nil {
+		return errors.Join(errors.New("handler: could not handle /map command:"), err)
+	}
+
+	return nil
+}
+
+// Same as /map, but for everyone
+func GlobalMapHandler(ctx *telegohandler.Context, update telego.Update) error {
+	if !configs.IsAdmin(update.Message.From.ID) {
+		if err := sendMessage(ctx, update, "Only admins can do that, boss."); err != nil {
+			return errors.Join(errors.New("handler: could not handle /globalmap command: not an admin:"), err)
+		}
+
+		return nil
+	}
+
+	if err := handleMapCommand(ctx, update, models.GlobalOverrideUserId); err != nil {
+		return errors.Join(errors.New("handler: could not handle /globalmap command:"), err)
+	}
+
+	return nil
+}
+
+func handleMapCommand(ctx *telegohandler.Context, update telego.Update, overrideUserId int64) error {
+	args := strings.Fields(getCommandArgument(update.Message.Text))
+	if len(args) < 2 {
+		if err := sendMessage(ctx, update, mapUsage); err != nil {
+			return errors.Join(errors.New("not enough arguments:"), err)
+		}
+
+		return nil
+	}
+
+	slug := parseGameSlug(strings.Join(args[:len(args)-1], " "))
+	target := args[len(args)-1]
+
+	var err error
+	var message string
+	switch strings.ToLower(target) {
+	case "reset":
+		err = repos.DeleteStoreOverride(overrideUserId, slug, igdb.StoreSteam)
+		message = fmt.Sprintf("Dropped Steam mapping for %s, boss.", slug)
+	case "none":
+		err = repos.UpsertStoreOverride(models.StoreOverride{UserId: overrideUserId, Slug: slug, Store: igdb.StoreSteam, Excluded: true})
+		message = fmt.Sprintf("Won't check Steam for %s anymore, boss.", slug)
+	default:
+		steamItemId, isParsed := parseSteamItemId(target)
+		if !isParsed {
+			if err := sendMessage(ctx, update, "Cannot confirm this is a Steam store link. Try another one, boss."); err != nil {
+				return errors.Join(errors.New("not a steam url:"), err)
+			}
+
+			return nil
+		}
+
+		err = repos.UpsertStoreOverride(models.StoreOverride{UserId: overrideUserId, Slug: slug, Store: igdb.StoreSteam, Uids: []string{steamItemId.Uid()}})
+		message = fmt.Sprintf("Got it, boss. %s is Steam %s %d from now on.", slug, steamItemId.Kind, steamItemId.Id)
+	}
+
+	if err != nil {
+		if err := sendMessage(ctx, update, "Couldn't update this mapping for some reason. Try again later, boss."); err != nil {
+			return errors.Join(errors.New("could not update override:"), err)
+		}
+
+		return nil
+	}
+
+	if err := sendMessage(ctx, update, message); err != nil {
+		return errors.Join(errors.New("send confirmation message:"), err)
+	}
+
+	return nil
+}
+
+// Accepts Backloggd game link, slug or title
+func parseGameSlug(game string) string {
+	if match := backloggdGameUrlRegexp.FindStringSubmatch(game); match != nil {
+		return match[1]
+	}
+
+	// Backloggd drops apostrophes instead of turning them into separators: baldurs-gate-3
+	game = strings.NewReplacer("'", "", "’", "").Replace(strings.ToLower(game))
+
+	return strings.Trim(slugSeparatorRegexp.ReplaceAllString(game, "-"), "-")
+}
+
+// Accepts Steam store link of an app, package or bundle, or bare app id
+func parseSteamItemId(target string) (steam.ItemId, bool) {
+	if match := steamItemUrlRegexp.FindStringSubmatch(target); match != nil {
+		target = match[1] + "/" + match[2]
+	}
+
+	steamItemId, err := steam.ParseItemId(target)
+	if err != nil {
+		return steam.ItemId{}, false
+	}
+
+	return steamItemId, true
+}
+
+// User overrides win over global ones. Overridden slugs without igdb record get a placeholder game,
+// so they are still priced. Names hold every igdb game of the wishlist, including filtered out ones
+func applyStoreOverrides(igdbGames []igdb.Game, overrides []models.StoreOverride, names map[string]string) []igdb.Game {
+	if len(overrides) == 0 {
+		return igdbGames
+	}
+
+	overridesBySlug := make(map[string]map[igdb.Store]models.StoreOverride)
+	for _, override := range overrides {
+		if overridesBySlug[override.Slug] == nil {
+			overridesBySlug[override.Slug] = make(map[igdb.Store]models.StoreOverride)
+		}
+
+		existingOverride, isExists := overridesBySlug[override.Slug][override.Store]
+		if isExists && existingOverride.UserId != models.GlobalOverrideUserId {
+			continue
+		}
+
+		overridesBySlug[override.Slug][override.Store] = override
+	}
+
+	var overriddenGames []igdb.Game
+	seenSlugs := make(map[string]bool)
+	for _, igdbGame := range igdbGames {
+		seenSlugs[igdbGame.Slug] = true
+		if storeOverrides, isExists := overridesBySlug[igdbGame.Slug]; isExists {
+			igdbGame.StoreIds = overrideStoreIds(igdbGame.StoreIds, storeOverrides)
+		}
+
+		overriddenGames = append(overriddenGames, igdbGame)
+	}
+
+	for slug, storeOverrides := range overridesBySlug {
+		// Known games missing here were filtered out on purpose
+		if _, isKnown := names[slug]; isKnown || seenSlugs[slug] {
+			continue
+		}
+
+		overriddenGames = append(overriddenGames, igdb.Game{
+			Slug:     slug,
+			Name:     slug,
+			StoreIds: overrideStoreIds(nil, storeOverrides),
+		})
+	}
+
+	return overriddenGames
+}
+
+func overrideStoreIds(storeIds []igdb.StoreId, storeOverrides map[igdb.Store]models.StoreOverride) []igdb.StoreId {
+	var overriddenStoreIds []igdb.StoreId
+	for _, storeId := range storeIds {
+		if _, isExists := storeOverrides[storeId.Store]; !isExists {
+			overriddenStoreIds = append(overriddenStoreIds, storeId)
+		}
+	}
+
+	for store, override := range storeOverrides {
+		if override.Excluded {
+			continue
+		}
+
+		for _, uid := range override.Uids {
+			overriddenStoreIds = append(overriddenStoreIds, igdb.StoreId{Store: store, Uid: uid})
+		}
+	}
+
+	return overriddenStoreIds
+}
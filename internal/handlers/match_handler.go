@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/mymmrac/telego/telegoutil"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	MatchCallbackPrefix = "match:"
+
+	// Best candidate must score at least this much and beat the runner-up by the margin to be taken without asking
+	confidentMatchScore  = 0.9
+	confidentMatchMargin = 0.1
+	// Candidates below this are not worth showing
+	minCandidateScore = 0.5
+
+	unmatchedRetryInterval = 7 * 24 * time.Hour
+)
+
+// Finds igdb games for slugs igdb does not know by searching scraped titles.
+// Runs once for the missing slugs of all users, matched games are returned by igdb id
+func resolveMissingSlugs(ctx context.Context, missingSlugs []string, titles map[string]string, now time.Time) (map[string]models.SlugMatch, map[uint64]igdb.Game, error) {
+	existingMatches, err := runStore.GetSlugMatches(missingSlugs)
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("could not get slug matches:"), err)
+	}
+
+	matchesBySlug := make(map[string]models.SlugMatch)
+	for _, match := range existingMatches {
+		matchesBySlug[match.BackloggdSlug] = match
+	}
+
+	var matchedIds []uint64
+	for _, slug := range missingSlugs {
+		match, isExists := matchesBySlug[slug]
+
+		isStale := match.Status == models.SlugMatchStatusUnmatched && now.Sub(match.CheckedAt) > unmatchedRetryInterval
+		if !isExists || isStale {
+			newMatch, err := searchSlugMatch(ctx, slug, getSlugTitle(slug, titles), now)
+			if err != nil {
+				// Out of time for the whole run, not just this slug
+				if ctx.Err() != nil {
+					return nil, nil, errors.Join(fmt.Errorf("could not search slug match: %s", slug), err)
+				}
+
+				// Nothing is stored, so the slug is searched again next run
+				log.Printf("handler: leaving slug unmatched for now: %s: %v", slug, err)
+				continue
+			}
+
+			storedMatch, err := runStore.UpsertSlugMatch(newMatch)
+			if err != nil {
+				return nil, nil, errors.Join(fmt.Errorf("could not store slug match: %s", slug), err)
+			}
+
+			match = *storedMatch
+			matchesBySlug[slug] = match
+		}
+
+		if match.Status == models.SlugMatchStatusMatched {
+			matchedIds = append(matchedIds, match.IgdbId)
+		}
+	}
+
+	matchedGames := make(map[uint64]igdb.Game)
+	if len(matchedIds) == 0 {
+		return matchesBySlug, matchedGames, nil
+	}
+
+	games, err := upstreams.Igdb.RequestGamesByIds(ctx, matchedIds)
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("could not request matched igdb games:"), err)
+	}
+
+	for _, game := range games {
+		matchedGames[game.Id] = game
+	}
+
+	return matchesBySlug, matchedGames, nil
+}
+
+func searchSlugMatch(ctx context.Context, slug string, title string, now time.Time) (models.SlugMatch, error) {
+	match := models.SlugMatch{
+		BackloggdSlug: slug,
+		Title:         title,
+		Status:        models.SlugMatchStatusUnmatched,
+		CheckedAt:     now,
+	}
+
+	searchedGames, err := upstreams.Igdb.SearchGames(ctx, title, igdbSearchLimit)
+	if err != nil {
+		return match, errors.Join(errors.New("could not search igdb games:"), err)
+	}
+
+	alternativeGames, err := upstreams.Igdb.RequestGamesByAlternativeName(ctx, title, igdbSearchLimit)
+	if err != nil {
+		return match, errors.Join(errors.New("could not request igdb games by alternative name:"), err)
+	}
+
+	candidatesById := make(map[uint64]models.SlugMatchCandidate)
+	for _, game := range append(searchedGames, alternativeGames...) {
+		score := scoreMatchCandidate(title, game)
+		if score >= minCandidateScore {
+			candidatesById[game.Id] = models.SlugMatchCandidate{IgdbId: game.Id, Name: game.Name, Score: score}
+		}
+	}
+
+	for _, candidate := range candidatesById {
+		match.Candidates = append(match.Candidates, candidate)
+	}
+
+	sort.Slice(match.Candidates, func(i, j int) bool {
+		return match.Candidates[i].Score > match.Candidates[j].Score
+	})
+
+	if len(match.Candidates) > igdbSearchLimit {
+		match.Candidates = match.Candidates[:igdbSearchLimit]
+	}
+
+	switch {
+	case len(match.Candidates) == 0:
+		match.Status = models.SlugMatchStatusUnmatched
+	case match.Candidates[0].Score >= confidentMatchScore && (len(match.Candidates) == 1 || match.Candidates[0].Score-match.Candidates[1].Score >= confidentMatchMargin):
+		match.Status = models.SlugMatchStatusMatched
+		match.IgdbId = match.Candidates[0].IgdbId
+	default:
+		match.Status = models.SlugMatchStatusAmbiguous
+	}
+
+	return match, nil
+}
+
+// Best similarity of title to the game name or any of its alternative names
+func scoreMatchCandidate(title string, game igdb.Game) float64 {
+	normalizedTitle := normalizeGameTitle(title)
+
+	bestScore := getSimilarity(normalizedTitle, normalizeGameTitle(game.Name))
+	for _, alternativeName := range game.AlternativeNames {
+		bestScore = max(bestScore, getSimilarity(normalizedTitle, normalizeGameTitle(alternativeName.Name)))
+	}
+
+	return bestScore
+}
+
+// Lowercase letters and digits separated by single spaces
+func normalizeGameTitle(title string) string {
+	var builder strings.Builder
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			builder.WriteRune(r)
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+
+	return strings.Join(strings.Fields(builder.String()), " ")
+}
+
+// One minus normalized levenshtein distance
+func getSimilarity(a string, b string) float64 {
+	if a == b {
+		return 1
+	}
+
+	aRunes, bRunes := []rune(a), []rune(b)
+	if len(aRunes) == 0 || len(bRunes) == 0 {
+		return 0
+	}
+
+	previous := make([]int, len(bRunes)+1)
+	current := make([]int, len(bRunes)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(aRunes); i++ {
+		current[0] = i
+		for j := 1; j <= len(bRunes); j++ {
+			cost := 1
+			if aRunes[i-1] == bRunes[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(bRunes)])/float64(max(len(aRunes), len(bRunes)))
+}
+
+// Falls back to the slug itself when the title was not scraped
+func getSlugTitle(slug string, titles map[string]string) string {
+	if title, isExists := titles[slug]; isExists {
+		return title
+	}
+
+	return strings.ReplaceAll(slug, "-", " ")
+}
+
+func isUserNotified(match models.SlugMatch, userId int64) bool {
+	for _, notifiedUserId := range match.NotifiedUserIds {
+		if notifiedUserId == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isMatchCandidate(match models.SlugMatch, igdbId uint64) bool {
+	for _, candidate := range match.Candidates {
+		if candidate.IgdbId == igdbId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func sendAmbiguousMatches(ctx *telegohandler.Context, userId int64, matches []models.SlugMatch) error {
+	for _, match := range matches {
+		var rows [][]telego.InlineKeyboardButton
+		for _, candidate := range match.Candidates {
+			button := telegoutil.InlineKeyboardButton(candidate.Name).WithCallbackData(fmt.Sprintf("%s%s:%d", MatchCallbackPrefix, match.Id.Hex(), candidate.IgdbId))
+			rows = append(rows, telegoutil.InlineKeyboardRow(button))
+		}
+
+		noneButton := telegoutil.InlineKeyboardButton("None of these").WithCallbackData(fmt.Sprintf("%s%s:0", MatchCallbackPrefix, match.Id.Hex()))
+		rows = append(rows, telegoutil.InlineKeyboardRow(noneButton))
+
+		if _, err := ctx.Bot().SendMessage(ctx, telegoutil.Message(
+			telegoutil.ID(userId),
+			fmt.Sprintf("Boss, I couldn't tell which game \"%s\" from your wishlist is. Which one is it?", match.Title),
+		).WithReplyMarkup(telegoutil.InlineKeyboard(rows...))); err != nil {
+			return errors.Join(fmt.Errorf("could not send ambiguous match: %s", match.BackloggdSlug), err)
+		}
+
+		if err := repos.AddSlugMatchNotifiedUser(match.Id, userId); err != nil {
+			return errors.Join(fmt.Errorf("could not mark ambiguous match notified: %s", match.BackloggdSlug), err)
+		}
+	}
+
+	return nil
+}
+
+func MatchCallbackHandler(ctx *telegohandler.Context, update telego.Update) error {
+	parts := strings.Split(strings.TrimPrefix(update.CallbackQuery.Data, MatchCallbackPrefix), ":")
+	if len(parts) != 2 {
+		return fmt.Errorf("handler: could not parse match callback: %s", update.CallbackQuery.Data)
+	}
+
+	matchId, err := bson.ObjectIDFromHex(parts[0])
+	if err != nil {
+		return errors.Join(fmt.Errorf("handler: could not parse match id from callback: %s", update.CallbackQuery.Data), err)
+	}
+
+	igdbId, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return errors.Join(fmt.Errorf("handler: could not parse igdb id from callback: %s", update.CallbackQuery.Data), err)
+	}
+
+	match, err := repos.GetSlugMatchById(matchId)
+	if err != nil || match == nil {
+		if err := answerCallback(ctx, update, "Couldn't find this game anymore. Try again later, boss."); err != nil {
+			return errors.Join(errors.New("handler: could not handle match callback: could not get match:"), err)
+		}
+
+		return nil
+	}
+
+	// Matches are shared by everyone, so only users who were asked may answer and only with an offered game
+	if !isUserNotified(*match, update.CallbackQuery.From.ID) || (igdbId != 0 && !isMatchCandidate(*match, igdbId)) {
+		if err := answerCallback(ctx, update, "This question wasn't for you, boss."); err != nil {
+			return errors.Join(errors.New("handler: could not handle match callback: not allowed:"), err)
+		}
+
+		return nil
+	}
+
+	status := models.SlugMatchStatusMatched
+	message := fmt.Sprintf("Got it, boss. Tracking \"%s\" from now on.", match.Title)
+	if igdbId == 0 {
+		status = models.SlugMatchStatusRejected
+		message = fmt.Sprintf("Got it, boss. I will leave \"%s\" alone.", match.Title)
+	}
+
+	if err := repos.UpdateSlugMatchStatus(matchId, status, igdbId); err != nil {
+		if err := answerCallback(ctx, update, "Couldn't save your answer for some reason. Try again later, boss."); err != nil {
+			return errors.Join(errors.New("handler: could not handle match callback: could not update match:"), err)
+		}
+
+		return nil
+	}
+
+	if err := answerCallback(ctx, update, message); err != nil {
+		return errors.Join(errors.New("handler: could not handle match callback: send confirmation message:"), err)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+)
+
+func TestGetSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want float64
+	}{
+		{name: "same", a: "portal 2", b: "portal 2", want: 1},
+		{name: "both empty", a: "", b: "", want: 1},
+		{name: "one empty", a: "portal", b: "", want: 0},
+		{name: "one edit", a: "portal", b: "portel", want: 1 - 1.0/6},
+		{name: "longer string sets the scale", a: "doom", b: "doom 3", want: 1 - 2.0/6},
+		{name: "runes, not bytes", a: "pokémon", b: "pokemon", want: 1 - 1.0/7},
+		{name: "nothing in common", a: "abc", b: "xyz", want: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := getSimilarity(test.a, test.b); math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("got %f, want %f", got, test.want)
+			}
+		})
+	}
+}
+
+func TestScoreMatchCandidate(t *testing.T) {
+	game := decodeFixture[igdb.Game](t, `{
+		"id": 1942, "name": "The Witcher 3: Wild Hunt", "slug": "the-witcher-3-wild-hunt",
+		"alternative_names": [{"id": 1, "name": "Wiedźmin 3: Dziki Gon"}]
+	}`)
+
+	tests := []struct {
+		name     string
+		title    string
+		minScore float64
+		maxScore float64
+	}{
+		{name: "punctuation and case are ignored", title: "the witcher 3 - WILD HUNT", minScore: 1, maxScore: 1},
+		{name: "alternative name counts", title: "Wiedźmin 3: Dziki Gon", minScore: 1, maxScore: 1},
+		{name: "close title is confident", title: "The Witcher 3 Wild Hunts", minScore: confidentMatchScore, maxScore: 1},
+		{name: "other game is below candidates", title: "Hollow Knight", minScore: 0, maxScore: minCandidateScore},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := scoreMatchCandidate(test.title, game); got < test.minScore || got > test.maxScore {
+				t.Errorf("got %f, want between %f and %f", got, test.minScore, test.maxScore)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/mymmrac/telego/telegoutil"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+)
+
+const (
+	// Telegram allows up to 10 photos in a media group, bigger digests go as text
+	maxMediaSales = 10
+	// Captions are capped at 1024 characters, the deal itself takes a couple hundred
+	maxSaleDescriptionLength = 600
+)
+
+// Prices are also shown in the display currency when it is set, numbers follow the user's language
+func sendSales(ctx *telegohandler.Context, userId int64, sales []models.Sale, displayCurrency string, language string) error {
+	if len(sales) > maxMediaSales || !hasImages(sales) {
+		return sendSalesText(ctx, userId, sales, displayCurrency, language)
+	}
+
+	if err := sendSalesMedia(ctx, userId, sales, displayCurrency, language); err != nil {
+		// Telegram could fail to fetch an image, deals are still worth delivering
+		if err := sendSalesText(ctx, userId, sales, displayCurrency, language); err != nil {
+			return errors.Join(errors.New("could not send sales as media or text:"), err)
+		}
+	}
+
+	return nil
+}
+
+func sendSalesText(ctx *telegohandler.Context, userId int64, sales []models.Sale, displayCurrency string, language string) error {
+	var fullMessage string
+	for _, sale := range sales {
+		fullMessage = fullMessage + formatSale(sale, displayCurrency, language) + "\n"
+	}
+
+	if _, err := ctx.Bot().SendMessage(ctx, &telego.SendMessageParams{
+		ChatID:    telegoutil.ID(userId),
+		ParseMode: "HTML",
+		Text:      fullMessage,
+	}); err != nil {
+		return errors.Join(errors.New("could not send message:"), err)
+	}
+
+	return nil
+}
+
+func sendSalesMedia(ctx *telegohandler.Context, userId int64, sales []models.Sale, displayCurrency string, language string) error {
+	fileIds, err := getCachedFileIds(sales)
+	if err != nil {
+		return errors.Join(errors.New("could not get cached telegram files:"), err)
+	}
+
+	// Single photo can not be sent as media group
+	if len(sales) == 1 {
+		message, err := ctx.Bot().SendPhoto(ctx, telegoutil.Photo(
+			telegoutil.ID(userId),
+			getSaleImageFile(sales[0], fileIds),
+		).WithCaption(formatSaleCaption(sales[0], displayCurrency, language)).WithParseMode("HTML"))
+		if err != nil {
+			return errors.Join(errors.New("could not send photo:"), err)
+		}
+
+		cacheSentFileIds(sales, []telego.Message{*message}, fileIds)
+		return nil
+	}
+
+	var media []telego.InputMedia
+	for _, sale := range sales {
+		media = append(media, telegoutil.MediaPhoto(getSaleImageFile(sale, fileIds)).WithCaption(formatSaleCaption(sale, displayCurrency, language)).WithParseMode("HTML"))
+	}
+
+	messages, err := ctx.Bot().SendMediaGroup(ctx, telegoutil.MediaGroup(telegoutil.ID(userId), media...))
+	if err != nil {
+		return errors.Join(errors.New("could not send media group:"), err)
+	}
+
+	cacheSentFileIds(sales, messages, fileIds)
+	return nil
+}
+
+// Deals are already delivered at this point, failing here would only send them again as text
+func cacheSentFileIds(sales []models.Sale, messages []telego.Message, fileIds map[string]string) {
+	if err := cacheFileIds(sales, messages, fileIds); err != nil {
+		log.Printf("handler: could not cache telegram files: %v", err)
+	}
+}
+
+func formatSale(sale models.Sale, displayCurrency string, language string) string {
+	// Captions are sent with html parse mode, store titles often carry "&"
+	name := fmt.Sprintf("<a href=\"%s\"><b>%s</b></a>", html.EscapeString(sale.Url), html.EscapeString(sale.Name))
+	if sale.Edition != "" {
+		name = fmt.Sprintf("%s (%s)", name, html.EscapeString(sale.Edition))
+	}
+
+	if sale.Giveaway {
+		return fmt.Sprintf("%s\nFree to keep! <s>%s</s>", name, sale.InitialPrice.FormatLocale(language))
+	}
+
+	finalPrice := sale.FinalPrice.FormatLocale(language)
+	if displayCurrency != "" && displayCurrency != sale.FinalPrice.Currency {
+		if convertedPrice, isConverted := getExchangeRates().ConvertMoney(sale.FinalPrice, displayCurrency); isConverted {
+			finalPrice = fmt.Sprintf("%s (~%s)", finalPrice, convertedPrice.FormatLocale(language))
+		}
+	}
+
+	message := fmt.Sprintf("%s\n%s -%d%% <s>%s</s>", name, finalPrice, sale.DiscountPercent, sale.InitialPrice.FormatLocale(language))
+	if sale.Reviews.TotalReviews > 0 {
+		message += fmt.Sprintf("\n<i>%s, %d%% of %d reviews</i>", html.EscapeString(sale.Reviews.ScoreDescription), sale.Reviews.GetPositivePercent(), sale.Reviews.TotalReviews)
+	}
+
+	return message
+}
+
+// Photos have room for the store description, text digests stay one deal per couple of lines
+func formatSaleCaption(sale models.Sale, displayCurrency string, language string) string {
+	caption := formatSale(sale, displayCurrency, language)
+	if sale.Description == "" {
+		return caption
+	}
+
+	// Steam sends descriptions with html entities, they are escaped again for the caption
+	description := []rune(html.UnescapeString(sale.Description))
+	if len(description) > maxSaleDescriptionLength {
+		description = append(description[:maxSaleDescriptionLength], '…')
+	}
+
+	return caption + "\n\n" + html.EscapeString(string(description))
+}
+
+func hasImages(sales []models.Sale) bool {
+	for _, sale := range sales {
+		if sale.Image == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func getCachedFileIds(sales []models.Sale) (map[string]string, error) {
+	var urls []string
+	for _, sale := range sales {
+		urls = append(urls, sale.Image)
+	}
+
+	files, err := repos.GetTelegramFiles(urls)
+	if err != nil {
+		return nil, err
+	}
+
+	fileIds := make(map[string]string)
+	for _, file := range files {
+		fileIds[file.Url] = file.FileId
+	}
+
+	return fileIds, nil
+}
+
+func getSaleImageFile(sale models.Sale, fileIds map[string]string) telego.InputFile {
+	if fileId, isExists := fileIds[sale.Image]; isExists {
+		return telegoutil.FileFromID(fileId)
+	}
+
+	return telegoutil.FileFromURL(sale.Image)
+}
+
+// Messages come back in the same order photos were sent
+func cacheFileIds(sales []models.Sale, messages []telego.Message, fileIds map[string]string) error {
+	for i, message := range messages {
+		if i >= len(sales) || len(message.Photo) == 0 {
+			continue
+		}
+
+		if _, isExists := fileIds[sales[i].Image]; isExists {
+			continue
+		}
+
+		// Last size is the biggest one
+		file := models.TelegramFile{
+			Url:    sales[i].Image,
+			FileId: message.Photo[len(message.Photo)-1].FileID,
+		}
+
+		if err := repos.UpsertTelegramFile(file); err != nil {
+			return errors.Join(errors.New("could not cache telegram file:"), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+// Keeps release watches in sync with unreleased games and returns news worth telling the user.
+// Released and undated games are returned separately, only they are worth pricing
+func trackReleases(userId int64, igdbGames []igdb.Game, now time.Time) ([]igdb.Game, []string, error) {
+	watches, err := runStore.GetReleaseWatches(userId)
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("could not get release watches:"), err)
+	}
+
+	watchesBySlug := make(map[string]models.ReleaseWatch)
+	for _, watch := range watches {
+		watchesBySlug[watch.Slug] = watch
+	}
+
+	var releasedGames []igdb.Game
+	var news []string
+	trackedSlugs := types.NewSet()
+	for _, igdbGame := range igdbGames {
+		trackedSlugs.Add(igdbGame.Slug)
+		watch, isWatched := watchesBySlug[igdbGame.Slug]
+
+		// News is sent with html parse mode
+		name := html.EscapeString(igdbGame.Name)
+
+		if igdbGame.FirstReleaseDate != 0 && !igdbGame.IsUnreleased(now) {
+			releasedGames = append(releasedGames, igdbGame)
+
+			if isWatched {
+				news = append(news, fmt.Sprintf("<b>%s</b> is out now, I will keep an eye on its price", name))
+
+				if err := runStore.DeleteReleaseWatch(userId, igdbGame.Slug); err != nil {
+					return nil, nil, errors.Join(fmt.Errorf("could not delete release watch: %s", igdbGame.Slug), err)
+				}
+			}
+
+			continue
+		}
+
+		// Undated games are priced in case Steam sells them already, and stay watched until they get a date
+		if igdbGame.FirstReleaseDate == 0 {
+			releasedGames = append(releasedGames, igdbGame)
+		}
+
+		releaseHuman := igdbGame.GetReleaseDateHuman()
+		if isWatched && watch.ReleaseDate != igdbGame.FirstReleaseDate {
+			if watch.ReleaseDate == 0 {
+				news = append(news, fmt.Sprintf("<b>%s</b> got a release date: %s", name, html.EscapeString(releaseHuman)))
+			} else if igdbGame.FirstReleaseDate != 0 {
+				news = append(news, fmt.Sprintf("<b>%s</b> moved from %s to %s", name, html.EscapeString(watch.ReleaseHuman), html.EscapeString(releaseHuman)))
+			}
+		}
+
+		if !isWatched || watch.ReleaseDate != igdbGame.FirstReleaseDate || watch.ReleaseHuman != releaseHuman {
+			newWatch := models.ReleaseWatch{
+				UserId:       userId,
+				Slug:         igdbGame.Slug,
+				Name:         igdbGame.Name,
+				ReleaseDate:  igdbGame.FirstReleaseDate,
+				ReleaseHuman: releaseHuman,
+			}
+
+			if err := runStore.UpsertReleaseWatch(newWatch); err != nil {
+				return nil, nil, errors.Join(fmt.Errorf("could not upsert release watch: %s", igdbGame.Slug), err)
+			}
+		}
+	}
+
+	// Games dropped from the wishlist are not worth watching anymore
+	for _, watch := range watches {
+		if !trackedSlugs.Contains(watch.Slug) {
+			if err := runStore.DeleteReleaseWatch(userId, watch.Slug); err != nil {
+				return nil, nil, errors.Join(fmt.Errorf("could not delete release watch: %s", watch.Slug), err)
+			}
+		}
+	}
+
+	return releasedGames, news, nil
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+)
+
+// Review scores move slowly, so summaries are kept for a few days
+const steamReviewSummaryTtl = 3 * 24 * time.Hour
+
+// Attaches review summaries to sales and drops the ones rated below the threshold.
+// Deals without reviews are kept, there is nothing to judge them by
+func applySteamReviews(sales []models.Sale, minReviewPercent int, reviewSummaries map[uint64]steam.ReviewSummary) []models.Sale {
+	var reviewedSales []models.Sale
+	for _, sale := range sales {
+		sale.Reviews = reviewSummaries[sale.SteamAppId]
+		if minReviewPercent > 0 && sale.Reviews.TotalReviews > 0 && sale.Reviews.GetPositivePercent() < minReviewPercent {
+			continue
+		}
+
+		reviewedSales = append(reviewedSales, sale)
+	}
+
+	return reviewedSales
+}
+
+// Sales of packages and bundles have no steam app id and are left out
+func getSalesSteamAppsIds(sales []models.Sale) []uint64 {
+	var steamAppsIds []uint64
+	for _, sale := range sales {
+		if sale.SteamAppId != 0 {
+			steamAppsIds = append(steamAppsIds, sale.SteamAppId)
+		}
+	}
+
+	return steamAppsIds
+}
+
+// Summaries Steam fails to give are skipped, reviews are nice to have
+func obtainSteamReviewSummaries(ctx context.Context, steamAppsIds []uint64, now time.Time) (map[uint64]steam.ReviewSummary, error) {
+	cachedSummaries, err := runStore.GetSteamReviewSummaries(steamAppsIds, now.Add(-steamReviewSummaryTtl))
+	if err != nil {
+		return nil, errors.Join(errors.New("could not get cached review summaries:"), err)
+	}
+
+	reviewSummaries := make(map[uint64]steam.ReviewSummary)
+	for _, reviewSummary := range cachedSummaries {
+		reviewSummaries[reviewSummary.SteamAppId] = reviewSummary
+	}
+
+	for _, steamAppId := range steamAppsIds {
+		// Out of time, apps not reviewed yet go without a summary
+		if ctx.Err() != nil {
+			break
+		}
+
+		if _, isExists := reviewSummaries[steamAppId]; isExists {
+			continue
+		}
+
+		reviewSummary, err := upstreams.Steam.RequestReviewSummary(ctx, steamAppId)
+		if err != nil {
+			log.Printf("handler: skipping review summary of steam app %d: %v", steamAppId, err)
+			continue
+		}
+
+		reviewSummary.CheckedAt = now
+		if err = runStore.UpsertSteamReviewSummary(reviewSummary); err != nil {
+			return nil, errors.Join(errors.New("could not cache review summary:"), err)
+		}
+
+		reviewSummaries[steamAppId] = reviewSummary
+	}
+
+	return reviewSummaries, nil
+}
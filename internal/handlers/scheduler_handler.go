@@ -1,52 +1,258 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"html"
+	"log"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/mymmrac/telego"
 	"github.com/mymmrac/telego/telegohandler"
 	"github.com/mymmrac/telego/telegoutil"
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
 	"github.com/theverysameliquidsnake/sales-bot/internal/models"
 	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
 	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
-	"github.com/theverysameliquidsnake/sales-bot/internal/parsers"
 	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
 	"github.com/theverysameliquidsnake/sales-bot/internal/requests"
 	"github.com/theverysameliquidsnake/sales-bot/internal/types"
 )
 
+type wishlistChanges struct {
+	added   []string
+	removed []string
+}
+
+func (c wishlistChanges) isEmpty() bool {
+	return len(c.added) == 0 && len(c.removed) == 0
+}
+
+type wishlistReport struct {
+	sales    []models.Sale
+	changes  wishlistChanges
+	releases []string
+	// Wishlist games igdb could not match confidently, user picks the right one
+	ambiguousMatches []models.SlugMatch
+	// Game names by slug or steam app id, used to render changes
+	names map[string]string
+	// Deals that cost less in other regions, only for users who asked to compare
+	cheaperRegions []string
+}
+
+// Concurrency and deadline of scheduled runs, defaults apply until main loads them from env
+var runConfig = configs.DefaultRunConfig()
+
+func SetRunConfig(newRunConfig configs.RunConfig) {
+	runConfig = newRunConfig
+}
+
+// Where runs keep wishlists and caches, swapped for an in-memory store to run the pipeline offline
+var runStore repos.RunStore = repos.MongoRunStore{}
+
+func SetRunStore(newRunStore repos.RunStore) {
+	runStore = newRunStore
+}
+
 func RunScheduledNotifications(ctx *telegohandler.Context, update telego.Update) error {
+	runErr := runScheduledNotifications(ctx)
+
+	// Alerts go out even when the run failed, broken upstreams are the usual reason for that
+	if err := sendUpstreamAlerts(ctx); err != nil {
+		return errors.Join(runErr, errors.New("handler: could not send upstream alerts:"), err)
+	}
+
+	return runErr
+}
+
+func runScheduledNotifications(ctx *telegohandler.Context) error {
 	if err := runCleanup(); err != nil {
 		return errors.Join(errors.New("handler: could not clean mongo db:"), err)
 	}
 
+	// Stale rates still beat no conversion at all
+	if err := RefreshExchangeRates(); err != nil {
+		log.Printf("handler: keeping previous exchange rates: %v", err)
+	}
+
 	userSettings, err := repos.GetUserSettings()
 	if err != nil {
 		return errors.Join(errors.New("handler: could not get all user settings from mongo db:"), err)
 	}
 
+	var runs []*userRun
 	for _, settings := range userSettings {
-		sales, err := processWishlist(settings)
-		if err != nil {
-			return errors.Join(fmt.Errorf("handler: could not handle profile: %s", settings.BackloggdProfile), err)
+		runs = append(runs, newUserRun(settings))
+	}
+
+	prepareUserRuns(ctx, runs)
+
+	// Deadlines only bound the lookups, whoever got their deals priced gets a digest
+	forEachUserRun(ctx, runs, func(run *userRun) error {
+		return notifyUser(ctx, run)
+	})
+
+	return collectUserRunsErrors(runs)
+}
+
+// Everything up to the digests, Telegram is only touched when they are sent.
+// Deals are found within the run deadline, extras get a budget of their own and are left off once it runs out
+func prepareUserRuns(ctx context.Context, runs []*userRun) {
+	runCtx, cancelRun := context.WithTimeout(ctx, runConfig.Deadline)
+	defer cancelRun()
+
+	// Scraping is the only per user part, everything after it is looked up once for the union of all wishlists
+	forEachUserRun(runCtx, runs, func(run *userRun) error {
+		return collectUserWishlist(runCtx, run)
+	})
+	resolveUserRunsGames(runCtx, runs)
+	priceUserRuns(runCtx, runs)
+
+	extrasCtx, cancelExtras := context.WithTimeout(ctx, runConfig.ExtrasDeadline)
+	defer cancelExtras()
+
+	reviewUserRuns(extrasCtx, runs)
+	localizeUserRuns(extrasCtx, runs)
+	compareUserRuns(extrasCtx, runs)
+}
+
+// Users not reached before the deadline are failed with it and wait for the next run
+var errRunDeadline = errors.New("handler: run deadline exceeded")
+
+// One user's way through a scheduled run, every stage fills in a bit more of it
+type userRun struct {
+	settings           models.UserSettings
+	collected          collectedWishlist
+	report             wishlistReport
+	steamItemIds       []steam.ItemId
+	slugsBySteamItemId map[steam.ItemId]string
+	coversBySlug       map[string]string
+	// Set by the stage that failed, the user sits out the rest of the run
+	err error
+}
+
+func newUserRun(settings models.UserSettings) *userRun {
+	return &userRun{
+		settings:           settings,
+		report:             wishlistReport{names: make(map[string]string)},
+		slugsBySteamItemId: make(map[steam.ItemId]string),
+		coversBySlug:       make(map[string]string),
+	}
+}
+
+func getActiveUserRuns(runs []*userRun) []*userRun {
+	var activeRuns []*userRun
+	for _, run := range runs {
+		if run.err == nil {
+			activeRuns = append(activeRuns, run)
 		}
+	}
 
-		if len(sales) > 0 {
-			var fullMessage string
-			for _, sale := range sales {
-				message := fmt.Sprintf("<a href=\"%s\"><b>%s</b></a>\n%s %s <s>%s</s>\n", sale.Url, sale.Name, sale.FinalPrice, sale.Discount, sale.InitialPrice)
-				fullMessage = fullMessage + message
-			}
+	return activeRuns
+}
+
+// Runs a stage for the users still in the run, a few of them at a time
+func forEachUserRun(ctx context.Context, runs []*userRun, stage func(run *userRun) error) {
+	jobs := make(chan *userRun)
 
-			if _, err := ctx.Bot().SendMessage(ctx, &telego.SendMessageParams{
-				ChatID:    telegoutil.ID(settings.UserId),
-				ParseMode: "HTML",
-				Text:      fullMessage,
-			}); err != nil {
-				return errors.Join(errors.New("handler: could not send message:"), err)
+	var workers sync.WaitGroup
+	for range runConfig.Concurrency {
+		workers.Go(func() {
+			for run := range jobs {
+				run.err = stage(run)
 			}
+		})
+	}
+
+	for _, run := range getActiveUserRuns(runs) {
+		if ctx.Err() != nil {
+			run.err = errRunDeadline
+			continue
+		}
+
+		select {
+		case jobs <- run:
+		case <-ctx.Done():
+			run.err = errRunDeadline
+		}
+	}
+
+	close(jobs)
+	workers.Wait()
+}
+
+// Failing profiles are logged one by one, users cut by the deadline are only counted
+func collectUserRunsErrors(runs []*userRun) error {
+	var runErrs []error
+	skippedUsers := 0
+	for _, run := range runs {
+		switch {
+		case run.err == nil:
+		case errors.Is(run.err, errRunDeadline), errors.Is(run.err, context.DeadlineExceeded):
+			// Stages stop between users, requests in flight are cut by the same deadline
+			skippedUsers++
+		default:
+			err := errors.Join(fmt.Errorf("handler: could not handle profile: %s", getWishlistProfile(run.settings)), run.err)
+			log.Print(err)
+			runErrs = append(runErrs, err)
+		}
+	}
+
+	if skippedUsers > 0 {
+		runErrs = append(runErrs, fmt.Errorf("handler: run deadline of %s exceeded, skipped users: %d", runConfig.Deadline, skippedUsers))
+	}
+
+	return errors.Join(runErrs...)
+}
+
+func notifyUser(ctx *telegohandler.Context, run *userRun) error {
+	settings := run.settings
+	report := run.report
+
+	if settings.NotifyWishlistChanges && !report.changes.isEmpty() {
+		if _, err := ctx.Bot().SendMessage(ctx, &telego.SendMessageParams{
+			ChatID:    telegoutil.ID(settings.UserId),
+			ParseMode: "HTML",
+			Text:      formatWishlistChanges(report, settings.Language),
+		}); err != nil {
+			return errors.Join(errors.New("could not send wishlist changes message:"), err)
+		}
+	}
+
+	if len(report.ambiguousMatches) > 0 {
+		if err := sendAmbiguousMatches(ctx, settings.UserId, report.ambiguousMatches); err != nil {
+			return errors.Join(errors.New("could not send ambiguous matches:"), err)
+		}
+	}
+
+	if len(report.releases) > 0 {
+		if _, err := ctx.Bot().SendMessage(ctx, &telego.SendMessageParams{
+			ChatID:    telegoutil.ID(settings.UserId),
+			ParseMode: "HTML",
+			Text:      strings.Join(report.releases, "\n"),
+		}); err != nil {
+			return errors.Join(errors.New("could not send release news message:"), err)
+		}
+	}
+
+	if len(report.sales) > 0 {
+		if err := sendSales(ctx, settings.UserId, report.sales, settings.DisplayCurrency, settings.Language); err != nil {
+			return errors.Join(errors.New("could not send sales:"), err)
+		}
+	}
+
+	if len(report.cheaperRegions) > 0 {
+		if _, err := ctx.Bot().SendMessage(ctx, &telego.SendMessageParams{
+			ChatID:    telegoutil.ID(settings.UserId),
+			ParseMode: "HTML",
+			Text:      "<b>Cheaper elsewhere</b>\n" + strings.Join(report.cheaperRegions, "\n"),
+		}); err != nil {
+			return errors.Join(errors.New("could not send cheaper regions message:"), err)
 		}
 	}
 
@@ -68,6 +274,105 @@ func getMissingSlugs(wishlistSlugs []string, existingGames []igdb.Game) []string
 	return slugsSet.Values()
 }
 
+// Slugs and steam apps ids are compared separately, apps ids are reported as strings
+func getWishlistChanges(previousWishlist models.Wishlist, currentWishlist models.Wishlist) wishlistChanges {
+	changes := getKeysChanges(previousWishlist.SlugList, currentWishlist.SlugList)
+
+	appsIdsChanges := getKeysChanges(formatSteamAppsIds(previousWishlist.SteamAppIds), formatSteamAppsIds(currentWishlist.SteamAppIds))
+	changes.added = append(changes.added, appsIdsChanges.added...)
+	changes.removed = append(changes.removed, appsIdsChanges.removed...)
+
+	return changes
+}
+
+func getKeysChanges(previousKeys []string, currentKeys []string) wishlistChanges {
+	previousSet := types.NewSet()
+	for _, key := range previousKeys {
+		previousSet.Add(key)
+	}
+
+	currentSet := types.NewSet()
+	for _, key := range currentKeys {
+		currentSet.Add(key)
+	}
+
+	var changes wishlistChanges
+	for _, key := range currentSet.Values() {
+		if !previousSet.Contains(key) {
+			changes.added = append(changes.added, key)
+		}
+	}
+
+	for _, key := range previousSet.Values() {
+		if !currentSet.Contains(key) {
+			changes.removed = append(changes.removed, key)
+		}
+	}
+
+	sort.Strings(changes.added)
+	sort.Strings(changes.removed)
+
+	return changes
+}
+
+func formatSteamAppsIds(steamAppsIds []uint64) []string {
+	var keys []string
+	for _, steamAppId := range steamAppsIds {
+		keys = append(keys, strconv.FormatUint(steamAppId, 10))
+	}
+
+	return keys
+}
+
+func formatWishlistChanges(report wishlistReport, language string) string {
+	salesBySlug := make(map[string]models.Sale)
+	for _, sale := range report.sales {
+		if sale.Slug != "" {
+			salesBySlug[sale.Slug] = sale
+		}
+		if sale.SteamAppId != 0 {
+			salesBySlug[strconv.FormatUint(sale.SteamAppId, 10)] = sale
+		}
+	}
+
+	var added []string
+	for _, slug := range report.changes.added {
+		name, isExists := report.names[slug]
+		if !isExists {
+			name = slug
+		}
+
+		// Sent with html parse mode, titles like "Ratchet & Clank" would break it
+		name = html.EscapeString(name)
+		if sale, isExists := salesBySlug[slug]; isExists {
+			name = fmt.Sprintf("%s (%s -%d%%)", name, sale.FinalPrice.FormatLocale(language), sale.DiscountPercent)
+		}
+
+		added = append(added, name)
+	}
+
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, "Now tracking: "+strings.Join(added, ", "))
+	}
+
+	var removed []string
+	for _, key := range report.changes.removed {
+		name, isExists := report.names[key]
+		if !isExists {
+			name = key
+		}
+
+		removed = append(removed, html.EscapeString(name))
+	}
+
+	if len(removed) > 0 {
+		parts = append(parts, "stopped tracking: "+strings.Join(removed, ", "))
+	}
+
+	return strings.Join(parts, "; ")
+}
+
 func getMissingSteamAppsIds(parsedSteamAppsIds []uint64, existingSteamAppsDetails []steam.AppDetails) ([]uint64, error) {
 	idsSet := types.NewSet()
 	for _, parsedSteamAppId := range parsedSteamAppsIds {
@@ -94,10 +399,7 @@ func getMissingSteamAppsIds(parsedSteamAppsIds []uint64, existingSteamAppsDetail
 }
 
 func runCleanup() error {
-	if err := repos.DropWishlists(); err != nil {
-		return errors.Join(errors.New("could not drop wishlists:"), err)
-	}
-
+	// Wishlists are kept between runs to detect changes
 	if err := repos.DropIgdbGames(); err != nil {
 		return errors.Join(errors.New("could not drop igdb games:"), err)
 	}
@@ -109,135 +411,602 @@ func runCleanup() error {
 	return nil
 }
 
-func obtainIgdbGames(slugs []string) ([]igdb.Game, error) {
-	existingGames, err := repos.GetIgdbGames(slugs)
+// Igdb games of all wishlists in the run, looked up once no matter how many users share them
+type igdbCatalog struct {
+	gamesBySlug map[string]igdb.Game
+	// Slugs igdb does not know, resolved by scraped titles
+	matchesBySlug map[string]models.SlugMatch
+	matchedGames  map[uint64]igdb.Game
+}
+
+func obtainIgdbCatalog(ctx context.Context, slugs []string, titles map[string]string) (igdbCatalog, error) {
+	catalog := igdbCatalog{gamesBySlug: make(map[string]igdb.Game)}
+	if err := fillIgdbGames(ctx, slugs); err != nil {
+		return catalog, err
+	}
+
+	games, err := runStore.GetIgdbGames(slugs)
+	if err != nil {
+		return catalog, errors.Join(errors.New("could not get igdb games from mongo db:"), err)
+	}
+
+	for _, game := range games {
+		catalog.gamesBySlug[game.Slug] = game
+	}
+
+	// Backloggd slugs mostly match igdb ones, the rest is looked up by title
+	missingSlugs := getMissingSlugs(slugs, games)
+	if len(missingSlugs) == 0 {
+		return catalog, nil
+	}
+
+	catalog.matchesBySlug, catalog.matchedGames, err = resolveMissingSlugs(ctx, missingSlugs, titles, time.Now())
 	if err != nil {
-		return nil, errors.Join(errors.New("could not check for existing igdb records:"), err)
+		return catalog, errors.Join(errors.New("could not resolve missing slugs:"), err)
+	}
+
+	return catalog, nil
+}
+
+// Ambiguous matches are returned until the user has been asked about them. Matched games carry
+// the wishlist slug, so overrides, release watches and names follow what the user has listed
+func (c igdbCatalog) getGames(userId int64, slugs []string) ([]igdb.Game, []models.SlugMatch) {
+	var games []igdb.Game
+	var ambiguousMatches []models.SlugMatch
+	seenIds := make(map[uint64]bool)
+	for _, slug := range slugs {
+		game, isExists := c.gamesBySlug[slug]
+		if !isExists {
+			match := c.matchesBySlug[slug]
+			switch match.Status {
+			case models.SlugMatchStatusMatched:
+				game, isExists = c.matchedGames[match.IgdbId]
+				game.Slug = slug
+			case models.SlugMatchStatusAmbiguous:
+				if !isUserNotified(match, userId) {
+					ambiguousMatches = append(ambiguousMatches, match)
+				}
+			}
+		}
+
+		if isExists && !seenIds[game.Id] {
+			games = append(games, game)
+			seenIds[game.Id] = true
+		}
+	}
+
+	return games, ambiguousMatches
+}
+
+// Caches are written one caller at a time, otherwise a command and a run sharing a game would insert it twice
+var (
+	igdbGamesFillMutex        sync.Mutex
+	steamAppsDetailsFillMutex sync.Mutex
+)
+
+func fillIgdbGames(ctx context.Context, slugs []string) error {
+	igdbGamesFillMutex.Lock()
+	defer igdbGamesFillMutex.Unlock()
+
+	existingGames, err := runStore.GetIgdbGames(slugs)
+	if err != nil {
+		return errors.Join(errors.New("could not check for existing igdb records:"), err)
 	}
 
 	// Add missing games if any
 	slugsToRequest := getMissingSlugs(slugs, existingGames)
-	if len(slugsToRequest) > 0 {
-		games, err := requests.RequestGamesFromIgdb(slugsToRequest)
+	if len(slugsToRequest) == 0 {
+		return nil
+	}
+
+	games, err := upstreams.Igdb.RequestGamesBySlugs(ctx, slugsToRequest)
+	if err != nil {
+		return errors.Join(errors.New("could not get games from igdb:"), err)
+	}
+
+	if len(games) > 0 {
+		if err = runStore.InsertIgdbGames(games); err != nil {
+			return errors.Join(errors.New("could not insert games from igdb:"), err)
+		}
+	}
+
+	return nil
+}
+
+// Packages and bundles are left out, they have no steam app id
+func getSteamAppsIds(igdbGame igdb.Game) []uint64 {
+	var steamAppsIds []uint64
+	for _, itemId := range getSteamItemIds(igdbGame) {
+		if itemId.Kind == steam.ItemApp {
+			steamAppsIds = append(steamAppsIds, itemId.Id)
+		}
+	}
+
+	return steamAppsIds
+}
+
+// Malformed uids are skipped, so one bad igdb record does not break the whole run
+func getSteamItemIds(igdbGame igdb.Game) []steam.ItemId {
+	var steamItemIds []steam.ItemId
+	for _, uid := range igdbGame.GetStoreUids(igdb.StoreSteam) {
+		steamItemId, err := steam.ParseItemId(uid)
 		if err != nil {
-			return nil, errors.Join(errors.New("could not get games from igdb:"), err)
+			log.Printf("handler: skipping malformed steam uid %q of igdb game: %s", uid, igdbGame.Slug)
+			continue
 		}
 
-		if err = repos.InsertIgdbGames(games); err != nil {
-			return nil, errors.Join(errors.New("could not insert games from igdb:"), err)
+		steamItemIds = append(steamItemIds, steamItemId)
+	}
+
+	return steamItemIds
+}
+
+func mapSlugsBySteamItemId(igdbGames []igdb.Game) map[steam.ItemId]string {
+	slugsBySteamItemId := make(map[steam.ItemId]string)
+	for _, igdbGame := range igdbGames {
+		for _, steamItemId := range getSteamItemIds(igdbGame) {
+			slugsBySteamItemId[steamItemId] = igdbGame.Slug
 		}
 	}
 
-	games, err := repos.GetIgdbGames(slugs)
+	return slugsBySteamItemId
+}
+
+func getSteamStoreUrl(steamAppId uint64) string {
+	return fmt.Sprintf("https://store.steampowered.com/app/%d/", steamAppId)
+}
+
+func obtainSteamAppsDetails(ctx context.Context, steamAppsIds []uint64, countryCode string) ([]steam.AppDetails, error) {
+	countryCode = strings.ToLower(countryCode)
+	if err := fillSteamAppsDetails(ctx, steamAppsIds, countryCode); err != nil {
+		return nil, err
+	}
+
+	appsDetails, err := runStore.GetSteamAppsDetails(steamAppsIds, countryCode)
 	if err != nil {
-		return nil, errors.Join(errors.New("could not get igdb games from mongo db:"), err)
+		return nil, errors.Join(errors.New("could not get app details from mongo db:"), err)
 	}
 
-	return games, nil
+	return appsDetails, nil
 }
 
-func extractSteamAppsIdsFromExternalIgdbGames(igdbGames []igdb.Game) ([]uint64, error) {
-	var steamAppsIds []uint64
-	for _, igdbGame := range igdbGames {
-		for _, externalGame := range igdbGame.ExternalGames {
-			if externalGame.ExternalGameSource.Name == "Steam" {
-				steamAppId, err := strconv.ParseUint(externalGame.Uid, 10, 64)
-				if err != nil {
-					return nil, errors.Join(fmt.Errorf("could not parse external game uid to uint: %s", externalGame.Uid), err)
-				}
+// Steam is asked without holding the lock, so a command does not wait behind the pricing of a whole run.
+// Apps cached by someone else in the meantime are dropped before inserting
+func fillSteamAppsDetails(ctx context.Context, steamAppsIds []uint64, countryCode string) error {
+	countryCode = strings.ToLower(countryCode)
 
-				steamAppsIds = append(steamAppsIds, steamAppId)
-			}
+	idsToRequest, err := getUncachedSteamAppsIds(steamAppsIds, countryCode)
+	if err != nil {
+		return err
+	}
+
+	if len(idsToRequest) == 0 {
+		return nil
+	}
+
+	appsDetails, appsErrors := upstreams.Steam.RequestAppDetails(ctx, idsToRequest, countryCode)
+	if err := checkSteamItemsErrors(steam.ItemApp, len(appsDetails), appsErrors); err != nil {
+		return errors.Join(errors.New("could not get apps details from steam:"), err)
+	}
+
+	// Remember apps Steam does not sell in the country, so they are not requested again this run
+	for steamAppId, err := range appsErrors {
+		if errors.Is(err, requests.ErrSteamAppUnavailable) {
+			appsDetails = append(appsDetails, steam.AppDetails{SteamAppId: steamAppId, Unavailable: true})
+		}
+	}
+
+	steamAppsDetailsFillMutex.Lock()
+	defer steamAppsDetailsFillMutex.Unlock()
+
+	stillMissingIds, err := getUncachedSteamAppsIds(idsToRequest, countryCode)
+	if err != nil {
+		return err
+	}
+
+	isStillMissing := make(map[uint64]bool)
+	for _, steamAppId := range stillMissingIds {
+		isStillMissing[steamAppId] = true
+	}
+
+	var newAppsDetails []steam.AppDetails
+	for _, appDetails := range appsDetails {
+		if isStillMissing[appDetails.SteamAppId] {
+			appDetails.CountryCode = countryCode
+			newAppsDetails = append(newAppsDetails, appDetails)
+		}
+	}
+
+	if len(newAppsDetails) > 0 {
+		if err = runStore.InsertSteamAppsDetails(newAppsDetails); err != nil {
+			return errors.Join(errors.New("could not insert apps details from steam:"), err)
 		}
 	}
 
-	return steamAppsIds, nil
+	return nil
 }
 
-func obtainSteamAppsDetails(steamAppsIds []uint64, userSettings models.UserSettings) ([]steam.AppDetails, error) {
-	existingSteamAppsDetails, err := repos.GetSteamAppsDetails(steamAppsIds)
+func getUncachedSteamAppsIds(steamAppsIds []uint64, countryCode string) ([]uint64, error) {
+	existingSteamAppsDetails, err := runStore.GetSteamAppsDetails(steamAppsIds, countryCode)
 	if err != nil {
 		return nil, errors.Join(errors.New("could not check for existing steam record:"), err)
 	}
 
-	// Add missing apps details if any
-	idsToRequest, err := getMissingSteamAppsIds(steamAppsIds, existingSteamAppsDetails)
+	missingIds, err := getMissingSteamAppsIds(steamAppsIds, existingSteamAppsDetails)
 	if err != nil {
 		return nil, errors.Join(errors.New("could not get missing steam apps ids difference:"), err)
 	}
 
-	if len(idsToRequest) > 0 {
-		appsDetails, err := requests.RequestAppDetailsFromSteam(idsToRequest, userSettings.CountryCode)
+	return missingIds, nil
+}
+
+// Unavailable items are expected, other failures are logged and skipped unless nothing came through at all.
+// Items cut by the context fail the whole batch
+func checkSteamItemsErrors(itemKind steam.ItemKind, receivedCount int, itemsErrors map[uint64]error) error {
+	var failures []error
+	for itemId, err := range itemsErrors {
+		// A cut batch fails as a whole, there is no point in logging every item of it
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+
+		if errors.Is(err, requests.ErrSteamAppUnavailable) || errors.Is(err, requests.ErrSteamPackageUnavailable) {
+			continue
+		}
+
+		log.Printf("handler: skipping steam %s %d: %v", itemKind, itemId, err)
+		failures = append(failures, err)
+	}
+
+	if receivedCount == 0 && len(failures) > 0 {
+		return errors.Join(failures...)
+	}
+
+	return nil
+}
+
+func getWishlistProfile(userSettings models.UserSettings) string {
+	if userSettings.WishlistSource == models.WishlistSourceSteam {
+		return userSettings.SteamProfile
+	}
+
+	return userSettings.BackloggdProfile
+}
+
+type collectedWishlist struct {
+	// Need igdb lookup
+	slugs []string
+	// Can be priced directly
+	steamAppsIds []uint64
+	// Scraped titles by slug, used when igdb does not know the slug
+	titles map[string]string
+	// Steam part is empty then, the stored wishlist is kept until the profile opens again
+	isSteamPrivate bool
+}
+
+func collectWishlist(ctx context.Context, userSettings models.UserSettings) (collectedWishlist, error) {
+	collected := collectedWishlist{titles: make(map[string]string)}
+	var slugs []string
+
+	switch userSettings.WishlistSource {
+	case models.WishlistSourceSteam:
+		steamWishlist, err := upstreams.Steam.RequestWishlist(ctx, userSettings.SteamProfile)
+		switch {
+		case errors.Is(err, requests.ErrSteamWishlistUnavailable):
+			// Private profile is the user's choice, watched games are still priced
+			log.Printf("handler: steam wishlist is private: %s", userSettings.SteamProfile)
+			collected.isSteamPrivate = true
+		case err != nil:
+			return collected, errors.Join(fmt.Errorf("could not request steam wishlist: %s", userSettings.SteamProfile), err)
+		}
+
+		collected.steamAppsIds = steamWishlist
+	default:
+		backloggdWishlist, err := upstreams.Backloggd.ParseWishlist(ctx, userSettings.BackloggdProfile)
 		if err != nil {
-			return nil, errors.Join(errors.New("could not get apps details from steam:"), err)
+			return collected, errors.Join(fmt.Errorf("could not parse profile: %s", userSettings.BackloggdProfile), err)
 		}
 
-		if err = repos.InsertSteamAppsDetails(appsDetails); err != nil {
-			return nil, errors.Join(errors.New("could not insert apps details from steam:"), err)
+		for _, scrapedGame := range backloggdWishlist {
+			slugs = append(slugs, scrapedGame.Slug)
+			if scrapedGame.Title != "" {
+				collected.titles[scrapedGame.Slug] = scrapedGame.Title
+			}
 		}
 	}
 
-	appsDetails, err := repos.GetSteamAppsDetails(steamAppsIds)
+	// Merge manually added games
+	watchlist, err := runStore.GetWatchlist(userSettings.UserId)
 	if err != nil {
-		return nil, errors.Join(errors.New("could not get app details from mongo db:"), err)
+		return collected, errors.Join(errors.New("could not get watchlist:"), err)
 	}
 
-	return appsDetails, nil
+	slugsSet := types.NewSet()
+	for _, slug := range slugs {
+		slugsSet.Add(slug)
+	}
+
+	for _, entry := range watchlist {
+		if entry.IgdbId == 0 {
+			collected.steamAppsIds = append(collected.steamAppsIds, entry.SteamAppId)
+		} else {
+			slugsSet.Add(entry.Slug)
+		}
+	}
+
+	collected.slugs = slugsSet.Values()
+
+	return collected, nil
 }
 
-func processWishlist(userSettings models.UserSettings) ([]models.Sale, error) {
-	slugs, err := parsers.ParseBackloggdWishlist(userSettings.BackloggdProfile)
+// Scrapes the profile and stores the wishlist, changes since the previous run go to the report
+func collectUserWishlist(ctx context.Context, run *userRun) error {
+	profile := getWishlistProfile(run.settings)
+
+	collected, err := collectWishlist(ctx, run.settings)
 	if err != nil {
-		return nil, errors.Join(fmt.Errorf("could not parse profile: %s", userSettings.BackloggdProfile), err)
+		return errors.Join(fmt.Errorf("could not collect wishlist: %s", profile), err)
+	}
+
+	run.collected = collected
+
+	// Hidden games are not gone, changes wait for the profile to open again
+	if collected.isSteamPrivate {
+		return nil
 	}
 
-	if len(slugs) == 0 {
-		return []models.Sale{}, nil
+	previousWishlist, err := runStore.GetWishlist(run.settings.UserId)
+	if err != nil {
+		return errors.Join(fmt.Errorf("could not get previous wishlist: %s", profile), err)
 	}
 
 	wishlist := models.Wishlist{
-		UserId:   userSettings.UserId,
-		SlugList: slugs,
+		UserId:      run.settings.UserId,
+		SlugList:    collected.slugs,
+		SteamAppIds: collected.steamAppsIds,
 	}
 
-	if err = repos.InsertWishlists([]models.Wishlist{wishlist}); err != nil {
-		return nil, errors.Join(fmt.Errorf("could not insert wishlists: %s", userSettings.BackloggdProfile), err)
+	// Nothing to compare against on the very first run
+	if previousWishlist != nil {
+		run.report.changes = getWishlistChanges(*previousWishlist, wishlist)
 	}
 
-	igdbGames, err := obtainIgdbGames(slugs)
-	if err != nil {
-		return nil, errors.Join(fmt.Errorf("could not obtain igdb games: %s", userSettings.BackloggdProfile), err)
+	if err = runStore.UpsertWishlist(wishlist); err != nil {
+		return errors.Join(fmt.Errorf("could not upsert wishlist: %s", profile), err)
 	}
 
-	// Only Steam for now
-	steamAppsIds, err := extractSteamAppsIdsFromExternalIgdbGames(igdbGames)
-	if err != nil {
-		return nil, errors.Join(fmt.Errorf("could not extract steam apps ids from igdb games: %s", userSettings.BackloggdProfile), err)
+	return nil
+}
+
+// Igdb is asked about the union of slugs once, then every user picks their games out of it
+func resolveUserRunsGames(ctx context.Context, runs []*userRun) {
+	slugsSet := types.NewSet()
+	titles := make(map[string]string)
+	for _, run := range getActiveUserRuns(runs) {
+		for _, slug := range run.collected.slugs {
+			slugsSet.Add(slug)
+		}
+
+		for slug, title := range run.collected.titles {
+			titles[slug] = title
+		}
 	}
 
-	if len(steamAppsIds) == 0 {
-		return []models.Sale{}, nil
+	var catalog igdbCatalog
+	var catalogErr error
+	switch {
+	case len(slugsSet.Values()) == 0:
+	case ctx.Err() != nil:
+		catalogErr = errRunDeadline
+	default:
+		catalog, catalogErr = obtainIgdbCatalog(ctx, slugsSet.Values(), titles)
 	}
 
-	steamAppsDetails, err := obtainSteamAppsDetails(steamAppsIds, userSettings)
+	forEachUserRun(ctx, runs, func(run *userRun) error {
+		if len(run.collected.slugs) > 0 && catalogErr != nil {
+			return errors.Join(errors.New("could not obtain igdb games:"), catalogErr)
+		}
+
+		return planSteamItems(run, catalog)
+	})
+}
+
+// Turns the user's games into steam items to price, minding filters, releases and store overrides
+func planSteamItems(run *userRun, catalog igdbCatalog) error {
+	profile := getWishlistProfile(run.settings)
+
+	if len(run.collected.slugs) > 0 {
+		igdbGames, ambiguousMatches := catalog.getGames(run.settings.UserId, run.collected.slugs)
+		run.report.ambiguousMatches = ambiguousMatches
+
+		for _, igdbGame := range igdbGames {
+			run.report.names[igdbGame.Slug] = igdbGame.Name
+			run.coversBySlug[igdbGame.Slug] = igdbGame.GetCoverUrl()
+		}
+
+		// Unreleased games have no price yet, they join pricing once out.
+		// Watches follow the whole wishlist, filters only hide games from deals
+		igdbGames, releases, err := trackReleases(run.settings.UserId, igdbGames, time.Now())
+		if err != nil {
+			return errors.Join(fmt.Errorf("could not track releases: %s", profile), err)
+		}
+
+		run.report.releases = releases
+		igdbGames = applyGameFilters(igdbGames, run.settings.Filters)
+
+		// Overrides fix wrong or missing store links from igdb, they are keyed by wishlist slugs
+		storeOverrides, err := runStore.GetStoreOverrides(run.settings.UserId, run.collected.slugs)
+		if err != nil {
+			return errors.Join(fmt.Errorf("could not get store overrides: %s", profile), err)
+		}
+
+		igdbGames = applyStoreOverrides(igdbGames, storeOverrides, run.report.names)
+
+		// Only Steam for now
+		for _, igdbGame := range igdbGames {
+			run.steamItemIds = append(run.steamItemIds, getSteamItemIds(igdbGame)...)
+		}
+
+		run.slugsBySteamItemId = mapSlugsBySteamItemId(igdbGames)
+	}
+
+	for _, steamAppId := range run.collected.steamAppsIds {
+		run.steamItemIds = append(run.steamItemIds, steam.ItemId{Kind: steam.ItemApp, Id: steamAppId})
+	}
+
+	return nil
+}
+
+// Every item is priced once per country, no matter how many users there wish for it
+func priceUserRuns(ctx context.Context, runs []*userRun) {
+	itemIdsByCountry := make(map[string][]steam.ItemId)
+	for _, run := range getActiveUserRuns(runs) {
+		countryCode := strings.ToLower(run.settings.CountryCode)
+		itemIdsByCountry[countryCode] = append(itemIdsByCountry[countryCode], run.steamItemIds...)
+	}
+
+	catalogs := make(map[string]steamCatalog)
+	catalogErrs := make(map[string]error)
+	for countryCode, itemIds := range itemIdsByCountry {
+		if len(itemIds) == 0 {
+			continue
+		}
+
+		if ctx.Err() != nil {
+			catalogErrs[countryCode] = errRunDeadline
+			continue
+		}
+
+		catalog, err := obtainSteamCatalog(ctx, itemIds, countryCode)
+		if err != nil {
+			catalogErrs[countryCode] = err
+			continue
+		}
+
+		catalogs[countryCode] = catalog
+	}
+
+	for _, run := range getActiveUserRuns(runs) {
+		if len(run.steamItemIds) == 0 {
+			continue
+		}
+
+		countryCode := strings.ToLower(run.settings.CountryCode)
+		if err := catalogErrs[countryCode]; err != nil {
+			run.err = errors.Join(fmt.Errorf("could not obtain steam editions: %s", getWishlistProfile(run.settings)), err)
+			continue
+		}
+
+		editions := catalogs[countryCode].getEditions(run.steamItemIds, run.report.names)
+		run.report.sales = pickCheapestEditions(editions, run.slugsBySteamItemId, run.report.names, run.coversBySlug)
+	}
+}
+
+// Review summaries of all deals are fetched together, then each user applies their own rating filter
+func reviewUserRuns(ctx context.Context, runs []*userRun) {
+	var steamAppsIds []uint64
+	var reviewedRuns []*userRun
+	for _, run := range getActiveUserRuns(runs) {
+		if len(run.report.sales) > 0 {
+			steamAppsIds = append(steamAppsIds, getSalesSteamAppsIds(run.report.sales)...)
+			reviewedRuns = append(reviewedRuns, run)
+		}
+	}
+
+	if len(reviewedRuns) == 0 {
+		return
+	}
+
+	// Reviews are an extra, deals still go out without them
+	if len(steamAppsIds) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	reviewSummaries, err := obtainSteamReviewSummaries(ctx, uniqueSteamAppsIds(steamAppsIds), time.Now())
 	if err != nil {
-		return nil, errors.Join(fmt.Errorf("could not obtain steam apps details: %s", userSettings.BackloggdProfile), err)
-	}
-
-	var steamSales []models.Sale
-	for _, steamAppDetails := range steamAppsDetails {
-		if steamAppDetails.PriceOverview.DiscountPercent > 0 {
-			steamSale := models.Sale{
-				Name:         steamAppDetails.Name,
-				Url:          fmt.Sprintf("https://store.steampowered.com/app/%d/", steamAppDetails.SteamAppId),
-				Discount:     fmt.Sprintf("-%d%%", steamAppDetails.PriceOverview.DiscountPercent),
-				InitialPrice: steamAppDetails.PriceOverview.InitialFormatted,
-				FinalPrice:   steamAppDetails.PriceOverview.FinalFormatted,
+		log.Printf("handler: sending deals without steam reviews: %v", err)
+		return
+	}
+
+	for _, run := range reviewedRuns {
+		run.report.sales = applySteamReviews(run.report.sales, run.settings.Filters.MinReviewPercent, reviewSummaries)
+	}
+}
+
+// Names are localized once per language for the apps of all users speaking it
+func localizeUserRuns(ctx context.Context, runs []*userRun) {
+	defaultLanguage := configs.GetSteamLanguage(configs.DefaultLanguage)
+
+	steamAppsIdsByLanguage := make(map[string][]uint64)
+	runsByLanguage := make(map[string][]*userRun)
+	for _, run := range getActiveUserRuns(runs) {
+		language := configs.GetSteamLanguage(run.settings.Language)
+		if language == defaultLanguage {
+			continue
+		}
+
+		steamAppsIdsByLanguage[language] = append(steamAppsIdsByLanguage[language], getLocalizableSteamAppsIds(run.report)...)
+		runsByLanguage[language] = append(runsByLanguage[language], run)
+	}
+
+	for language, steamAppsIds := range steamAppsIdsByLanguage {
+		// Localized names are a nicety, users still get English ones without them
+		if len(steamAppsIds) == 0 || ctx.Err() != nil {
+			continue
+		}
+
+		localizedNames, err := obtainSteamLocalizedNames(ctx, uniqueSteamAppsIds(steamAppsIds), language, time.Now())
+		if err != nil {
+			log.Printf("handler: keeping english steam names for %s: %v", language, err)
+			continue
+		}
+
+		for _, run := range runsByLanguage[language] {
+			localizeReport(&run.report, localizedNames)
+		}
+	}
+}
+
+// Deals of users who compare regions are priced abroad once, then every user reads them from the cache
+func compareUserRuns(ctx context.Context, runs []*userRun) {
+	// Own region is priced already, only the ones each user compares against are fetched
+	steamAppsIdsByCountry := make(map[string][]uint64)
+	var comparedRuns []*userRun
+	for _, run := range getActiveUserRuns(runs) {
+		if !run.settings.CompareRegions || len(run.report.sales) == 0 {
+			continue
+		}
+
+		steamAppsIds := getSalesSteamAppsIds(run.report.sales)
+		for _, countryCode := range getCompareCountries(run.settings) {
+			if !strings.EqualFold(countryCode, run.settings.CountryCode) {
+				steamAppsIdsByCountry[countryCode] = append(steamAppsIdsByCountry[countryCode], steamAppsIds...)
 			}
+		}
+		comparedRuns = append(comparedRuns, run)
+	}
+
+	// Comparison is a bonus, users still get their deals without it
+	if len(comparedRuns) == 0 || ctx.Err() != nil {
+		return
+	}
+
+	prefetchRegionalPrices(ctx, steamAppsIdsByCountry)
+
+	for _, run := range comparedRuns {
+		run.report.cheaperRegions = findCheaperRegions(ctx, run.report.sales, run.settings)
+	}
+}
 
-			steamSales = append(steamSales, steamSale)
+func uniqueSteamAppsIds(steamAppsIds []uint64) []uint64 {
+	var uniqueIds []uint64
+	seenIds := make(map[uint64]bool)
+	for _, steamAppId := range steamAppsIds {
+		if !seenIds[steamAppId] {
+			uniqueIds = append(uniqueIds, steamAppId)
+			seenIds[steamAppId] = true
 		}
 	}
 
-	return steamSales, nil
+	return uniqueIds
 }
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/fakes"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+const (
+	pipelineSteamId64         = "76561197960287930"
+	pipelinePrivateSteamId64  = "76561197960287931"
+	pipelineBackloggdUsername = "ann"
+	pipelineSaleAppId         = 367520
+	pipelineFullPriceAppId    = 620
+	pipelineDelistedAppId     = 999999
+)
+
+// Nested upstream types are unexported, fixtures are written the way upstreams answer
+func decodeFixture[T any](t *testing.T, fixture string) T {
+	t.Helper()
+
+	var value T
+	if err := json.Unmarshal([]byte(fixture), &value); err != nil {
+		t.Fatal(err)
+	}
+
+	return value
+}
+
+func newPipelineFixtures(t *testing.T) fakes.Fixtures {
+	return fakes.Fixtures{
+		IgdbGames: []igdb.Game{
+			decodeFixture[igdb.Game](t, fmt.Sprintf(`{
+				"id": 14593, "name": "Hollow Knight", "slug": "hollow-knight", "first_release_date": 1487894400,
+				"external_games": [{"id": 1, "uid": "%d", "external_game_source": {"id": %d, "name": "Steam"}}]
+			}`, pipelineSaleAppId, igdb.ExternalGameSourceSteam)),
+		},
+		SteamAppsDetails: map[uint64]steam.AppDetails{
+			pipelineSaleAppId: decodeFixture[steam.AppDetails](t, fmt.Sprintf(`{
+				"type": "game", "name": "Hollow Knight", "steam_appid": %d, "release_date": {"date": "24 Feb, 2017"},
+				"price_overview": {"currency": "USD", "discount_percent": 50, "initial": 1499, "final": 749}
+			}`, pipelineSaleAppId)),
+			pipelineFullPriceAppId: decodeFixture[steam.AppDetails](t, fmt.Sprintf(`{
+				"type": "game", "name": "Portal 2", "steam_appid": %d, "release_date": {"date": "18 Apr, 2011"},
+				"price_overview": {"currency": "USD", "discount_percent": 0, "initial": 999, "final": 999}
+			}`, pipelineFullPriceAppId)),
+		},
+		SteamReviewSummaries: map[uint64]steam.ReviewSummary{
+			pipelineSaleAppId: {ScoreDescription: "Overwhelmingly Positive", TotalPositive: 97, TotalReviews: 100},
+		},
+		// Delisted app is left out of the store on purpose
+		SteamWishlists: map[string][]uint64{
+			pipelineSteamId64: {pipelineFullPriceAppId, pipelineSaleAppId, pipelineDelistedAppId},
+		},
+		BackloggdWishlists: map[string][]models.ScrapedGame{
+			pipelineBackloggdUsername: {{Slug: "hollow-knight", Title: "Hollow Knight"}},
+		},
+	}
+}
+
+func useFakeUpstreams(t *testing.T, fixtures fakes.Fixtures) *fakes.Servers {
+	t.Helper()
+
+	servers := fakes.NewServers(fixtures)
+	t.Cleanup(servers.Close)
+
+	igdbTokens := configs.NewIgdbTokenManager(servers.Urls().TwitchOauth, fakes.IgdbClientId, fakes.IgdbClientSecret)
+	if err := igdbTokens.RequestToken(); err != nil {
+		t.Fatal(err)
+	}
+
+	previousUpstreams := upstreams
+	previousRunConfig := runConfig
+	t.Cleanup(func() {
+		SetUpstreams(previousUpstreams)
+		SetRunConfig(previousRunConfig)
+	})
+
+	SetUpstreams(NewUpstreams(servers.Urls(), igdbTokens))
+	SetRunConfig(configs.DefaultRunConfig())
+
+	return servers
+}
+
+func useFakeRunStore(t *testing.T) *fakes.RunStore {
+	t.Helper()
+
+	previousRunStore := runStore
+	t.Cleanup(func() {
+		SetRunStore(previousRunStore)
+	})
+
+	store := fakes.NewRunStore()
+	SetRunStore(store)
+
+	return store
+}
+
+func TestPrepareUserRuns(t *testing.T) {
+	store := useFakeRunStore(t)
+	servers := useFakeUpstreams(t, newPipelineFixtures(t))
+
+	steamRun := newUserRun(models.UserSettings{UserId: 1, WishlistSource: models.WishlistSourceSteam, SteamProfile: pipelineSteamId64, CountryCode: "us"})
+	backloggdRun := newUserRun(models.UserSettings{UserId: 2, WishlistSource: models.WishlistSourceBackloggd, BackloggdProfile: servers.BackloggdProfileUrl(pipelineBackloggdUsername), CountryCode: "us"})
+	privateRun := newUserRun(models.UserSettings{UserId: 3, WishlistSource: models.WishlistSourceSteam, SteamProfile: pipelinePrivateSteamId64, CountryCode: "us"})
+	runs := []*userRun{steamRun, backloggdRun, privateRun}
+
+	prepareUserRuns(context.Background(), runs)
+
+	// Private profiles are priced for their watchlist only, not failed
+	if privateRun.err != nil || !privateRun.collected.isSteamPrivate || len(privateRun.report.sales) != 0 {
+		t.Errorf("private wishlist: got error %v and %d sales, want a private run without sales", privateRun.err, len(privateRun.report.sales))
+	}
+
+	if err := collectUserRunsErrors(runs); err != nil {
+		t.Errorf("run failed: %v", err)
+	}
+
+	// Private wishlists keep whatever was stored before, so nothing is reported as removed next time
+	if wishlist, _ := store.GetWishlist(privateRun.settings.UserId); wishlist != nil {
+		t.Errorf("private wishlist: got stored wishlist %+v, want none", *wishlist)
+	}
+
+	if wishlist, _ := store.GetWishlist(backloggdRun.settings.UserId); wishlist == nil || len(wishlist.SlugList) != 1 {
+		t.Errorf("backloggd wishlist: got stored wishlist %+v, want one slug", wishlist)
+	}
+
+	// Full priced and delisted apps are no deals, both users see the same sale whatever the wishlist source
+	for _, run := range []*userRun{steamRun, backloggdRun} {
+		profile := getWishlistProfile(run.settings)
+		if run.err != nil {
+			t.Errorf("%s: unexpected error: %v", profile, run.err)
+			continue
+		}
+
+		if len(run.report.sales) != 1 {
+			t.Errorf("%s: got %d sales, want 1: %+v", profile, len(run.report.sales), run.report.sales)
+			continue
+		}
+
+		sale := run.report.sales[0]
+		if sale.SteamAppId != pipelineSaleAppId || sale.DiscountPercent != 50 {
+			t.Errorf("%s: got sale of app %d at %d%%, want app %d at 50%%", profile, sale.SteamAppId, sale.DiscountPercent, pipelineSaleAppId)
+		}
+
+		if want := types.NewMoney(749, "USD"); sale.FinalPrice != want {
+			t.Errorf("%s: got final price %+v, want %+v", profile, sale.FinalPrice, want)
+		}
+
+		if sale.Reviews.ScoreDescription != "Overwhelmingly Positive" {
+			t.Errorf("%s: got reviews %q, want the fixture summary", profile, sale.Reviews.ScoreDescription)
+		}
+	}
+}
+
+func TestGetKeysChanges(t *testing.T) {
+	tests := []struct {
+		name         string
+		previousKeys []string
+		currentKeys  []string
+		want         wishlistChanges
+	}{
+		{name: "first run adds everything", previousKeys: nil, currentKeys: []string{"portal-2", "celeste"}, want: wishlistChanges{added: []string{"celeste", "portal-2"}}},
+		{name: "emptied wishlist removes everything", previousKeys: []string{"portal-2", "620"}, currentKeys: nil, want: wishlistChanges{removed: []string{"620", "portal-2"}}},
+		{name: "both ways, duplicates once", previousKeys: []string{"celeste", "portal-2"}, currentKeys: []string{"portal-2", "hollow-knight", "hollow-knight"}, want: wishlistChanges{added: []string{"hollow-knight"}, removed: []string{"celeste"}}},
+		{name: "unchanged", previousKeys: []string{"celeste"}, currentKeys: []string{"celeste"}, want: wishlistChanges{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := getKeysChanges(test.previousKeys, test.currentKeys)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -2,16 +2,18 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/mymmrac/telego"
 	"github.com/mymmrac/telego/telegohandler"
 	"github.com/mymmrac/telego/telegoutil"
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
 	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
 )
 
 func StartHandler(ctx *telegohandler.Context, update telego.Update) error {
-	message := "Not so fast, boss. First, send me a link to your Backloggd profile using the /profile <url> command, both short and long ones are acceptable. Then tell me the country you have your Steam account registered in using the /country <country code> command so I could display the right currency."
+	message := "Not so fast, boss. First, send me a link to your Backloggd profile using the /profile <url> command, both short and long ones are acceptable. If you keep your wishlist on Steam, send me your public Steam profile using the /steam <profile> command instead. Then tell me the country you have your Steam account registered in using the /country <country code> command so I could display the right currency."
 	if err := sendMessage(ctx, update, message); err != nil {
 		return errors.Join(errors.New("handler: could not handle /start command:"), err)
 	}
@@ -54,12 +56,52 @@ func ProfileHandler(ctx *telegohandler.Context, update telego.Update) error {
 	return nil
 }
 
+func SteamHandler(ctx *telegohandler.Context, update telego.Update) error {
+	if len(strings.Split(strings.TrimSpace(update.Message.Text), " ")) < 2 {
+		message := "Boss, send me your public Steam profile link, SteamID64 or custom URL name using the /steam <profile> command."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /steam command: not enough arguments:"), err)
+		}
+
+		return nil
+	}
+
+	profile := strings.Split(strings.TrimSpace(update.Message.Text), " ")[1]
+	steamId64, err := upstreams.SteamProfiles.ParseSteamId64(ctx, profile)
+	if err != nil {
+		message := "Cannot find this Steam profile. Make sure it is public and try another one, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /steam command: not a Steam profile:"), err)
+		}
+
+		return nil
+	}
+
+	if err := repos.UpsertSteamProfileSetting(update.Message.Chat.ID, steamId64); err != nil {
+		message := "Couldn't update your Steam profile for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /steam command: could not upsert profile:"), err)
+		}
+
+		return nil
+	}
+
+	message := "Got your Steam profile updated, boss. I will track your Steam wishlist from now on."
+	if err := sendMessage(ctx, update, message); err != nil {
+		return errors.Join(errors.New("handler: could not handle /steam command: send confirmation message:"), err)
+	}
+
+	return nil
+}
+
 func CountryHandler(ctx *telegohandler.Context, update telego.Update) error {
 	if len(strings.Split(strings.TrimSpace(update.Message.Text), " ")) < 2 {
 		message := "Boss, tell me your country you have Steam registered in using the /country <country code> command."
 		if err := sendMessage(ctx, update, message); err != nil {
 			return errors.Join(errors.New("handler: could not handle /country command: not enough arguments:"), err)
 		}
+
+		return nil
 	}
 
 	countryCode := strings.Split(update.Message.Text, " ")[1]
@@ -68,16 +110,21 @@ func CountryHandler(ctx *telegohandler.Context, update telego.Update) error {
 		if err := sendMessage(ctx, update, message); err != nil {
 			return errors.Join(errors.New("handler: could not handle /country command: not a country code:"), err)
 		}
+
+		return nil
 	}
 
-	if err := repos.UpsertCountrySetting(update.Message.Chat.ID, countryCode, ""); err != nil {
+	currencyCode := configs.GetSteamCurrency(countryCode)
+	if err := repos.UpsertCountrySetting(update.Message.Chat.ID, countryCode, currencyCode); err != nil {
 		message := "Couldn't update your country for some reason. Try again later, boss."
 		if err := sendMessage(ctx, update, message); err != nil {
 			return errors.Join(errors.New("handler: could not handle /country command: could not upsert country code:"), err)
 		}
+
+		return nil
 	}
 
-	message := "Got your country updated, boss."
+	message := fmt.Sprintf("Got your country updated, boss. Steam should charge you in %s there.", currencyCode)
 	if err := sendMessage(ctx, update, message); err != nil {
 		return errors.Join(errors.New("handler: could not handle /country command: send confirmation message:"), err)
 	}
@@ -85,6 +132,39 @@ func CountryHandler(ctx *telegohandler.Context, update telego.Update) error {
 	return nil
 }
 
+func ChangesHandler(ctx *telegohandler.Context, update telego.Update) error {
+	args := strings.Split(strings.TrimSpace(update.Message.Text), " ")
+	if len(args) < 2 || (args[1] != "on" && args[1] != "off") {
+		message := "Boss, tell me whether you want to hear about wishlist changes using the /changes <on|off> command."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /changes command: not enough arguments:"), err)
+		}
+
+		return nil
+	}
+
+	notifyWishlistChanges := args[1] == "on"
+	if err := repos.UpsertWishlistChangesSetting(update.Message.Chat.ID, notifyWishlistChanges); err != nil {
+		message := "Couldn't update your wishlist changes setting for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /changes command: could not upsert setting:"), err)
+		}
+
+		return nil
+	}
+
+	message := "Got it, boss. I will keep quiet about wishlist changes."
+	if notifyWishlistChanges {
+		message = "Got it, boss. I will tell you what games you start and stop tracking."
+	}
+
+	if err := sendMessage(ctx, update, message); err != nil {
+		return errors.Join(errors.New("handler: could not handle /changes command: send confirmation message:"), err)
+	}
+
+	return nil
+}
+
 // Debug command
 func RefreshHandler(ctx *telegohandler.Context, update telego.Update) error {
 	if err := RunScheduledNotifications(ctx, update); err != nil {
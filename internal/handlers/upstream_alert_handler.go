@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/mymmrac/telego/telegoutil"
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+const (
+	// Fewer calls say nothing about the success rate
+	minAttemptsForSuccessRate = 10
+	minSuccessRate            = 0.5
+	// Drop from the previous run worth telling about, even above the minimum
+	maxSuccessRateDrop = 0.3
+)
+
+// Success rates of the previous run by upstream
+var (
+	previousSuccessRates      = make(map[string]float64)
+	previousSuccessRatesMutex sync.Mutex
+)
+
+// Without admin chat alerts only make it to the log
+func sendUpstreamAlerts(ctx *telegohandler.Context) error {
+	drifts, stats := upstreams.Monitor.Flush()
+
+	alerts := append(formatDriftAlerts(drifts), formatSuccessRateAlerts(stats)...)
+	if len(alerts) == 0 {
+		return nil
+	}
+
+	adminChatId, isSet := configs.GetAdminChatId()
+	if !isSet {
+		for _, alert := range alerts {
+			log.Printf("handler: upstream alert: %s", alert)
+		}
+
+		return nil
+	}
+
+	for _, alert := range alerts {
+		if _, err := ctx.Bot().SendMessage(ctx, &telego.SendMessageParams{
+			ChatID:    telegoutil.ID(adminChatId),
+			ParseMode: "HTML",
+			Text:      alert,
+		}); err != nil {
+			return errors.Join(errors.New("could not send alert:"), err)
+		}
+	}
+
+	return nil
+}
+
+func formatDriftAlerts(drifts []types.UpstreamDrift) []string {
+	var alerts []string
+	for _, drift := range drifts {
+		alerts = append(alerts, fmt.Sprintf("<b>%s looks changed</b>: %s\n<pre>%s</pre>", drift.Upstream, html.EscapeString(drift.Problem), html.EscapeString(drift.Sample)))
+	}
+
+	return alerts
+}
+
+func formatSuccessRateAlerts(stats map[string]types.UpstreamStats) []string {
+	var upstreamNames []string
+	for upstreamName := range stats {
+		upstreamNames = append(upstreamNames, upstreamName)
+	}
+	sort.Strings(upstreamNames)
+
+	previousSuccessRatesMutex.Lock()
+	defer previousSuccessRatesMutex.Unlock()
+
+	var alerts []string
+	for _, upstreamName := range upstreamNames {
+		upstreamStats := stats[upstreamName]
+		if upstreamStats.Attempts < minAttemptsForSuccessRate {
+			continue
+		}
+
+		successRate := upstreamStats.GetSuccessRate()
+		previousSuccessRate, isKnown := previousSuccessRates[upstreamName]
+		previousSuccessRates[upstreamName] = successRate
+
+		if successRate < minSuccessRate || (isKnown && previousSuccessRate-successRate >= maxSuccessRateDrop) {
+			alert := fmt.Sprintf("<b>%s success rate fell to %.0f%%</b> (%d of %d)", upstreamName, successRate*100, upstreamStats.Successes, upstreamStats.Attempts)
+			if isKnown {
+				alert += fmt.Sprintf(", it was %.0f%% last run", previousSuccessRate*100)
+			}
+
+			alerts = append(alerts, alert)
+		}
+	}
+
+	return alerts
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"sync/atomic"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/parsers"
+	"github.com/theverysameliquidsnake/sales-bot/internal/requests"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+// Upstream services used by handlers, swapped for fakes to run the pipeline offline
+type Upstreams struct {
+	Igdb          requests.IgdbApi
+	Steam         requests.SteamApi
+	Backloggd     parsers.BackloggdApi
+	SteamProfiles parsers.SteamProfileApi
+	// Collects schema drift and success rates of the upstreams above
+	Monitor *types.UpstreamMonitor
+	// Exchange rates are reloaded from here on every scheduled run
+	ExchangeRatesUrl string
+}
+
+var upstreams Upstreams
+
+func NewUpstreams(urls configs.UpstreamUrls, igdbTokens configs.IgdbTokenSource) Upstreams {
+	monitor := types.NewUpstreamMonitor()
+	// Clients hitting the same host share one limiter, whichever worker calls them
+	limiters := types.NewHostRateLimiters()
+
+	return Upstreams{
+		Igdb:             requests.NewIgdbClient(urls.Igdb, igdbTokens, limiters, monitor),
+		Steam:            requests.NewSteamClient(urls.SteamStore, urls.SteamApi, limiters, monitor),
+		Backloggd:        parsers.NewBackloggdParser(urls.Backloggd, limiters, monitor),
+		SteamProfiles:    parsers.NewSteamProfileParser(urls.SteamCommunity, limiters),
+		Monitor:          monitor,
+		ExchangeRatesUrl: urls.ExchangeRates,
+	}
+}
+
+func SetUpstreams(newUpstreams Upstreams) {
+	upstreams = newUpstreams
+}
+
+// Used to bring regional prices to one currency. Replaced whole by runs while commands read it
+var exchangeRates atomic.Pointer[models.ExchangeRates]
+
+// No rates before the first refresh, conversion is simply off then
+func getExchangeRates() models.ExchangeRates {
+	if currentExchangeRates := exchangeRates.Load(); currentExchangeRates != nil {
+		return *currentExchangeRates
+	}
+
+	return models.ExchangeRates{}
+}
+
+func RefreshExchangeRates() error {
+	newExchangeRates, err := configs.LoadExchangeRates(upstreams.ExchangeRatesUrl)
+	if err != nil {
+		return err
+	}
+
+	exchangeRates.Store(&newExchangeRates)
+	return nil
+}
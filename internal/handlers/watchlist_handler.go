@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/mymmrac/telego/telegoutil"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+)
+
+const (
+	WatchCallbackPrefix   = "watch:"
+	UnwatchCallbackPrefix = "unwatch:"
+
+	unwatchSteamCallbackPrefix = UnwatchCallbackPrefix + "steam:"
+
+	igdbSearchLimit = 5
+)
+
+func AddHandler(ctx *telegohandler.Context, update telego.Update) error {
+	name := getCommandArgument(update.Message.Text)
+	if name == "" {
+		message := "Boss, tell me what game you want to track using the /add <name> command."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /add command: not enough arguments:"), err)
+		}
+
+		return nil
+	}
+
+	games, err := upstreams.Igdb.SearchGames(ctx, name, igdbSearchLimit)
+	if err != nil {
+		message := "Couldn't search for this game for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /add command: could not search igdb:"), err)
+		}
+
+		return nil
+	}
+
+	if len(games) == 0 {
+		message := "Couldn't find anything like that. Try another name, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /add command: nothing found:"), err)
+		}
+
+		return nil
+	}
+
+	var rows [][]telego.InlineKeyboardButton
+	for _, game := range games {
+		button := telegoutil.InlineKeyboardButton(game.Name).WithCallbackData(fmt.Sprintf("%s%d", WatchCallbackPrefix, game.Id))
+		rows = append(rows, telegoutil.InlineKeyboardRow(button))
+	}
+
+	if _, err := ctx.Bot().SendMessage(ctx, telegoutil.Message(
+		telegoutil.ID(update.Message.Chat.ID),
+		"Which one do you mean, boss?",
+	).WithReplyMarkup(telegoutil.InlineKeyboard(rows...))); err != nil {
+		return errors.Join(errors.New("handler: could not handle /add command: could not send matches:"), err)
+	}
+
+	return nil
+}
+
+func WatchCallbackHandler(ctx *telegohandler.Context, update telego.Update) error {
+	igdbId, err := strconv.ParseUint(strings.TrimPrefix(update.CallbackQuery.Data, WatchCallbackPrefix), 10, 64)
+	if err != nil {
+		return errors.Join(fmt.Errorf("handler: could not parse igdb id from callback: %s", update.CallbackQuery.Data), err)
+	}
+
+	games, err := upstreams.Igdb.RequestGamesByIds(ctx, []uint64{igdbId})
+	if err != nil || len(games) == 0 {
+		if err := answerCallback(ctx, update, "Couldn't find this game anymore. Try again later, boss."); err != nil {
+			return errors.Join(errors.New("handler: could not handle watch callback: could not request igdb game:"), err)
+		}
+
+		return nil
+	}
+
+	entry := models.WatchlistEntry{
+		UserId: update.CallbackQuery.From.ID,
+		IgdbId: games[0].Id,
+		Slug:   games[0].Slug,
+		Name:   games[0].Name,
+	}
+
+	if err := repos.UpsertWatchlistEntry(entry); err != nil {
+		if err := answerCallback(ctx, update, "Couldn't update your watchlist for some reason. Try again later, boss."); err != nil {
+			return errors.Join(errors.New("handler: could not handle watch callback: could not upsert entry:"), err)
+		}
+
+		return nil
+	}
+
+	if err := answerCallback(ctx, update, fmt.Sprintf("Now tracking %s, boss.", entry.Name)); err != nil {
+		return errors.Join(errors.New("handler: could not handle watch callback: send confirmation message:"), err)
+	}
+
+	return nil
+}
+
+func RemoveHandler(ctx *telegohandler.Context, update telego.Update) error {
+	watchlist, err := runStore.GetWatchlist(update.Message.Chat.ID)
+	if err != nil {
+		message := "Couldn't get your watchlist for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /remove command: could not get watchlist:"), err)
+		}
+
+		return nil
+	}
+
+	name := strings.ToLower(getCommandArgument(update.Message.Text))
+
+	var rows [][]telego.InlineKeyboardButton
+	for _, entry := range watchlist {
+		if strings.Contains(strings.ToLower(entry.Name), name) {
+			callbackData := fmt.Sprintf("%s%d", UnwatchCallbackPrefix, entry.IgdbId)
+			if entry.IgdbId == 0 {
+				callbackData = fmt.Sprintf("%s%d", unwatchSteamCallbackPrefix, entry.SteamAppId)
+			}
+
+			button := telegoutil.InlineKeyboardButton(entry.Name).WithCallbackData(callbackData)
+			rows = append(rows, telegoutil.InlineKeyboardRow(button))
+		}
+	}
+
+	if len(rows) == 0 {
+		message := "Nothing like that in your watchlist, boss. Games from your Backloggd or Steam wishlist should be removed there."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /remove command: nothing found:"), err)
+		}
+
+		return nil
+	}
+
+	if _, err := ctx.Bot().SendMessage(ctx, telegoutil.Message(
+		telegoutil.ID(update.Message.Chat.ID),
+		"Which one should I stop tracking, boss?",
+	).WithReplyMarkup(telegoutil.InlineKeyboard(rows...))); err != nil {
+		return errors.Join(errors.New("handler: could not handle /remove command: could not send matches:"), err)
+	}
+
+	return nil
+}
+
+func UnwatchCallbackHandler(ctx *telegohandler.Context, update telego.Update) error {
+	userId := update.CallbackQuery.From.ID
+
+	var deleteErr error
+	if strings.HasPrefix(update.CallbackQuery.Data, unwatchSteamCallbackPrefix) {
+		steamAppId, err := strconv.ParseUint(strings.TrimPrefix(update.CallbackQuery.Data, unwatchSteamCallbackPrefix), 10, 64)
+		if err != nil {
+			return errors.Join(fmt.Errorf("handler: could not parse steam app id from callback: %s", update.CallbackQuery.Data), err)
+		}
+
+		deleteErr = repos.DeleteSteamWatchlistEntry(userId, steamAppId)
+	} else {
+		igdbId, err := strconv.ParseUint(strings.TrimPrefix(update.CallbackQuery.Data, UnwatchCallbackPrefix), 10, 64)
+		if err != nil {
+			return errors.Join(fmt.Errorf("handler: could not parse igdb id from callback: %s", update.CallbackQuery.Data), err)
+		}
+
+		deleteErr = repos.DeleteWatchlistEntry(userId, igdbId)
+	}
+
+	if deleteErr != nil {
+		if err := answerCallback(ctx, update, "Couldn't update your watchlist for some reason. Try again later, boss."); err != nil {
+			return errors.Join(errors.New("handler: could not handle unwatch callback: could not delete entry:"), deleteErr, err)
+		}
+
+		return nil
+	}
+
+	if err := answerCallback(ctx, update, "Stopped tracking it, boss."); err != nil {
+		return errors.Join(errors.New("handler: could not handle unwatch callback: send confirmation message:"), err)
+	}
+
+	return nil
+}
+
+func answerCallback(ctx *telegohandler.Context, update telego.Update, message string) error {
+	if err := ctx.Bot().AnswerCallbackQuery(ctx, telegoutil.CallbackQuery(update.CallbackQuery.ID).WithText(message)); err != nil {
+		return errors.Join(errors.New("could not answer callback query:"), err)
+	}
+
+	if _, err := ctx.Bot().SendMessage(ctx, telegoutil.Message(
+		telegoutil.ID(update.CallbackQuery.From.ID),
+		message,
+	)); err != nil {
+		return errors.Join(errors.New("could not send message:"), err)
+	}
+
+	return nil
+}
+
+// Everything after the command itself, so names with spaces are kept whole
+func getCommandArgument(text string) string {
+	parts := strings.SplitN(strings.TrimSpace(text), " ", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return strings.TrimSpace(parts[1])
+}
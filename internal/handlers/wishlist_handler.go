@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/mymmrac/telego"
+	"github.com/mymmrac/telego/telegohandler"
+	"github.com/mymmrac/telego/telegoutil"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"github.com/theverysameliquidsnake/sales-bot/internal/repos"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+// Telegram caps messages at 4096 characters, leave some room for markup
+const maxWishlistMessageLength = 4000
+
+type wishlistSection struct {
+	title string
+	lines []string
+}
+
+func WishlistHandler(ctx *telegohandler.Context, update telego.Update) error {
+	rows, err := collectExportRows(ctx, update.Message.Chat.ID)
+	if err != nil {
+		message := "Couldn't collect your games for some reason. Try again later, boss."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /wishlist command: could not collect rows:"), err)
+		}
+
+		return nil
+	}
+
+	if len(rows) == 0 {
+		message := "Nothing here yet, boss. I fill this in after checking your wishlist."
+		if err := sendMessage(ctx, update, message); err != nil {
+			return errors.Join(errors.New("handler: could not handle /wishlist command: nothing to show:"), err)
+		}
+
+		return nil
+	}
+
+	userSettings, err := repos.GetUserSettingsByUserId(update.Message.Chat.ID)
+	if err != nil {
+		return errors.Join(errors.New("handler: could not handle /wishlist command: could not get user settings:"), err)
+	}
+
+	language := ""
+	if userSettings != nil {
+		language = userSettings.Language
+	}
+
+	for _, message := range splitWishlistMessage(formatWishlistSections(rows, language)) {
+		if _, err := ctx.Bot().SendMessage(ctx, telegoutil.Message(
+			telegoutil.ID(update.Message.Chat.ID),
+			message,
+		).WithParseMode("HTML")); err != nil {
+			return errors.Join(errors.New("handler: could not handle /wishlist command: could not send message:"), err)
+		}
+	}
+
+	return nil
+}
+
+// Prices are rendered from amounts, numbers follow the user's language
+func formatWishlistSections(rows []exportRow, language string) []wishlistSection {
+	sections := []wishlistSection{
+		{title: "Free to keep right now"},
+		{title: "On sale"},
+		{title: "Full price"},
+		{title: "Free to play"},
+		{title: "Coming soon"},
+		{title: "Not sold in your region"},
+		{title: "Not on Steam or not checked yet"},
+	}
+
+	for _, row := range rows {
+		// Sent with html parse mode, titles like "Ratchet & Clank" would break it
+		name := html.EscapeString(row.Name)
+		if row.Url != "" {
+			name = fmt.Sprintf("<a href=\"%s\">%s</a>", html.EscapeString(row.Url), name)
+		}
+
+		switch steam.Availability(row.Availability) {
+		case steam.AvailabilityGiveaway:
+			sections[0].lines = append(sections[0].lines, fmt.Sprintf("%s <s>%s</s>", name, formatRowPrice(row.InitialPrice, language)))
+		case steam.AvailabilityPriced:
+			if row.DiscountPercent > 0 {
+				sections[1].lines = append(sections[1].lines, fmt.Sprintf("%s %s -%d%% <s>%s</s>", name, formatRowPrice(row.FinalPrice, language), row.DiscountPercent, formatRowPrice(row.InitialPrice, language)))
+			} else {
+				sections[2].lines = append(sections[2].lines, fmt.Sprintf("%s %s", name, formatRowPrice(row.FinalPrice, language)))
+			}
+		case steam.AvailabilityFree:
+			sections[3].lines = append(sections[3].lines, name)
+		case steam.AvailabilityComingSoon:
+			sections[4].lines = append(sections[4].lines, name)
+		case steam.AvailabilityUnavailable:
+			sections[5].lines = append(sections[5].lines, name)
+		default:
+			sections[6].lines = append(sections[6].lines, name)
+		}
+	}
+
+	return sections
+}
+
+func formatRowPrice(price *types.Money, language string) string {
+	if price == nil {
+		return ""
+	}
+
+	return price.FormatLocale(language)
+}
+
+// Fills messages line by line, so long wishlists span several of them
+func splitWishlistMessage(sections []wishlistSection) []string {
+	var messages []string
+	var current strings.Builder
+	for _, section := range sections {
+		if len(section.lines) == 0 {
+			continue
+		}
+
+		lines := append([]string{fmt.Sprintf("<b>%s</b>", section.title)}, section.lines...)
+		for i, line := range lines {
+			// Blank line between sections
+			separator := "\n"
+			if i == 0 {
+				separator = "\n\n"
+			}
+
+			if current.Len() > 0 && current.Len()+len(separator)+len(line) > maxWishlistMessageLength {
+				messages = append(messages, current.String())
+				current.Reset()
+			}
+
+			if current.Len() > 0 {
+				current.WriteString(separator)
+			}
+			current.WriteString(line)
+		}
+	}
+
+	if current.Len() > 0 {
+		messages = append(messages, current.String())
+	}
+
+	return messages
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitWishlistMessage(t *testing.T) {
+	longLine := strings.Repeat("a", maxWishlistMessageLength-100)
+
+	tests := []struct {
+		name     string
+		sections []wishlistSection
+		want     []string
+	}{
+		{
+			name:     "nothing to show",
+			sections: []wishlistSection{{title: "On sale"}},
+			want:     nil,
+		},
+		{
+			name:     "empty sections are skipped",
+			sections: []wishlistSection{{title: "Free to keep right now"}, {title: "On sale", lines: []string{"a", "b"}}, {title: "Full price", lines: []string{"c"}}},
+			want:     []string{"<b>On sale</b>\na\nb\n\n<b>Full price</b>\nc"},
+		},
+		{
+			name:     "long wishlists span several messages",
+			sections: []wishlistSection{{title: "On sale", lines: []string{longLine, longLine}}},
+			want:     []string{"<b>On sale</b>\n" + longLine, longLine},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := splitWishlistMessage(test.sections)
+			if len(got) != len(test.want) {
+				t.Fatalf("got %d messages, want %d", len(got), len(test.want))
+			}
+
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Errorf("message %d: got %q, want %q", i, got[i], test.want[i])
+				}
+
+				if len(got[i]) > maxWishlistMessageLength {
+					t.Errorf("message %d: got %d characters, want at most %d", i, len(got[i]), maxWishlistMessageLength)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package models
+
+import "github.com/theverysameliquidsnake/sales-bot/internal/types"
+
+// Rates are units of currency per one unit of base currency
+type ExchangeRates struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+func (r ExchangeRates) getRate(currency string) (float64, bool) {
+	if currency != "" && currency == r.Base {
+		return 1, true
+	}
+
+	rate, isExists := r.Rates[currency]
+	return rate, isExists && rate > 0
+}
+
+func (r ExchangeRates) IsKnown(currency string) bool {
+	_, isKnown := r.getRate(currency)
+	return isKnown
+}
+
+// Reports false when either currency has no known rate
+func (r ExchangeRates) Convert(amount float64, from string, to string) (float64, bool) {
+	if from == to {
+		return amount, true
+	}
+
+	fromRate, isFromKnown := r.getRate(from)
+	toRate, isToKnown := r.getRate(to)
+	if !isFromKnown || !isToKnown {
+		return 0, false
+	}
+
+	return amount / fromRate * toRate, true
+}
+
+func (r ExchangeRates) ConvertMoney(money types.Money, to string) (types.Money, bool) {
+	converted, isConverted := r.Convert(money.Float(), money.Currency, to)
+	if !isConverted {
+		return types.Money{}, false
+	}
+
+	return types.NewMoneyFromFloat(converted, to), true
+}
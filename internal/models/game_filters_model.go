@@ -0,0 +1,15 @@
+package models
+
+type GameFilters struct {
+	// Keep only games available on any of these platforms, matched by name or abbreviation
+	Platforms []string `bson:"platforms"`
+	// Drop games with any of these genres or themes
+	ExcludedGenres         []string `bson:"excluded_genres"`
+	ExcludeMultiplayerOnly bool     `bson:"exclude_multiplayer_only"`
+	// Drop deals with a lower share of positive Steam reviews, zero keeps everything
+	MinReviewPercent int `bson:"min_review_percent"`
+}
+
+func (f GameFilters) IsEmpty() bool {
+	return len(f.Platforms) == 0 && len(f.ExcludedGenres) == 0 && !f.ExcludeMultiplayerOnly && f.MinReviewPercent == 0
+}
@@ -1,5 +1,11 @@
 package igdb
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 type externalGameSource struct {
 	Id   uint64 `json:"id" bson:"id"`
 	Name string `json:"name" bson:"name"`
@@ -16,10 +22,102 @@ type cover struct {
 	ImageId string `json:"image_id" bson:"image_id"`
 }
 
+type platform struct {
+	Id           uint64 `json:"id" bson:"id"`
+	Name         string `json:"name" bson:"name"`
+	Abbreviation string `json:"abbreviation" bson:"abbreviation"`
+}
+
+// Shared shape of genres, themes, game modes and alternative names
+type category struct {
+	Id   uint64 `json:"id" bson:"id"`
+	Name string `json:"name" bson:"name"`
+}
+
+type releaseDate struct {
+	Id       uint64 `json:"id" bson:"id"`
+	Date     int64  `json:"date" bson:"date"`
+	Human    string `json:"human" bson:"human"`
+	Platform uint64 `json:"platform" bson:"platform"`
+}
+
 type Game struct {
-	Id            uint64         `json:"id" bson:"id"`
-	Name          string         `json:"name" bson:"name"`
-	Cover         cover          `json:"cover" bson:"cover"`
-	ExternalGames []externalGame `json:"external_games" bson:"external_games"`
-	Slug          string         `json:"slug" bson:"slug"`
+	Id               uint64         `json:"id" bson:"id"`
+	Name             string         `json:"name" bson:"name"`
+	Cover            cover          `json:"cover" bson:"cover"`
+	ExternalGames    []externalGame `json:"external_games" bson:"external_games"`
+	Slug             string         `json:"slug" bson:"slug"`
+	Platforms        []platform     `json:"platforms" bson:"platforms"`
+	Genres           []category     `json:"genres" bson:"genres"`
+	Themes           []category     `json:"themes" bson:"themes"`
+	GameModes        []category     `json:"game_modes" bson:"game_modes"`
+	FirstReleaseDate int64          `json:"first_release_date" bson:"first_release_date"`
+	ReleaseDates     []releaseDate  `json:"release_dates" bson:"release_dates"`
+	AlternativeNames []category     `json:"alternative_names" bson:"alternative_names"`
+	// Filled from external games after request, not part of igdb response
+	StoreIds []StoreId `json:"-" bson:"store_ids"`
+}
+
+// Empty if the game has no cover
+func (g Game) GetCoverUrl() string {
+	if g.Cover.ImageId == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("https://images.igdb.com/igdb/image/upload/t_cover_big/%s.jpg", g.Cover.ImageId)
+}
+
+// Case insensitive substring match, so "horror" also hits "Survival horror"
+func (g Game) HasGenreOrTheme(name string) bool {
+	name = strings.ToLower(name)
+	for _, genre := range g.Genres {
+		if strings.Contains(strings.ToLower(genre.Name), name) {
+			return true
+		}
+	}
+
+	for _, theme := range g.Themes {
+		if strings.Contains(strings.ToLower(theme.Name), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Igdb game mode ids
+const GameModeSinglePlayer = 1
+
+func (g Game) IsMultiplayerOnly() bool {
+	if len(g.GameModes) == 0 {
+		return false
+	}
+
+	for _, gameMode := range g.GameModes {
+		if gameMode.Id == GameModeSinglePlayer {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Only a known date in the future counts, undated games may well be on sale already
+func (g Game) IsUnreleased(now time.Time) bool {
+	return g.FirstReleaseDate > now.Unix()
+}
+
+// Human readable first release date, igdb provides text like "Q4 2026" for vague dates
+func (g Game) GetReleaseDateHuman() string {
+	if g.FirstReleaseDate == 0 {
+		return "TBA"
+	}
+
+	for _, releaseDate := range g.ReleaseDates {
+		if releaseDate.Date == g.FirstReleaseDate && releaseDate.Human != "" {
+			return releaseDate.Human
+		}
+	}
+
+	return time.Unix(g.FirstReleaseDate, 0).UTC().Format("Jan 02, 2006")
 }
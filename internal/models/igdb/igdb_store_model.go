@@ -0,0 +1,110 @@
+package igdb
+
+import "strings"
+
+type Store int
+
+const (
+	StoreUnknown Store = iota
+	StoreSteam
+	StoreGog
+	StoreEpic
+	StoreMicrosoft
+	StorePlayStation
+	StoreNintendo
+)
+
+// Igdb external game source ids
+const (
+	ExternalGameSourceSteam            = 1
+	ExternalGameSourceGog              = 5
+	ExternalGameSourceMicrosoft        = 11
+	ExternalGameSourceEpic             = 26
+	ExternalGameSourceXboxMarketplace  = 31
+	ExternalGameSourcePlayStationStore = 36
+)
+
+var storesByExternalGameSourceId = map[uint64]Store{
+	ExternalGameSourceSteam:            StoreSteam,
+	ExternalGameSourceGog:              StoreGog,
+	ExternalGameSourceMicrosoft:        StoreMicrosoft,
+	ExternalGameSourceEpic:             StoreEpic,
+	ExternalGameSourceXboxMarketplace:  StoreMicrosoft,
+	ExternalGameSourcePlayStationStore: StorePlayStation,
+}
+
+// Used for sources without a known id, such as Nintendo eShop
+var storesByExternalGameSourceName = map[string]Store{
+	"nintendo":    StoreNintendo,
+	"playstation": StorePlayStation,
+	"epic":        StoreEpic,
+	"gog":         StoreGog,
+	"microsoft":   StoreMicrosoft,
+	"xbox":        StoreMicrosoft,
+	"steam":       StoreSteam,
+}
+
+type StoreId struct {
+	Store Store  `json:"store" bson:"store"`
+	Uid   string `json:"uid" bson:"uid"`
+}
+
+func (s Store) String() string {
+	switch s {
+	case StoreSteam:
+		return "Steam"
+	case StoreGog:
+		return "GOG"
+	case StoreEpic:
+		return "Epic Games Store"
+	case StoreMicrosoft:
+		return "Microsoft Store"
+	case StorePlayStation:
+		return "PlayStation Store"
+	case StoreNintendo:
+		return "Nintendo eShop"
+	default:
+		return "Unknown"
+	}
+}
+
+func getStore(source externalGameSource) Store {
+	if store, isExists := storesByExternalGameSourceId[source.Id]; isExists {
+		return store
+	}
+
+	name := strings.ToLower(source.Name)
+	for prefix, store := range storesByExternalGameSourceName {
+		if strings.HasPrefix(name, prefix) {
+			return store
+		}
+	}
+
+	return StoreUnknown
+}
+
+// Collects ids of known stores from external games, unknown sources are dropped
+func ExtractStoreIds(externalGames []externalGame) []StoreId {
+	var storeIds []StoreId
+	for _, externalGame := range externalGames {
+		store := getStore(externalGame.ExternalGameSource)
+		if store == StoreUnknown || externalGame.Uid == "" {
+			continue
+		}
+
+		storeIds = append(storeIds, StoreId{Store: store, Uid: externalGame.Uid})
+	}
+
+	return storeIds
+}
+
+func (g Game) GetStoreUids(store Store) []string {
+	var uids []string
+	for _, storeId := range g.StoreIds {
+		if storeId.Store == store {
+			uids = append(uids, storeId.Uid)
+		}
+	}
+
+	return uids
+}
@@ -0,0 +1,9 @@
+package models
+
+type ReleaseWatch struct {
+	UserId       int64  `bson:"user_id"`
+	Slug         string `bson:"slug"`
+	Name         string `bson:"name"`
+	ReleaseDate  int64  `bson:"release_date"`
+	ReleaseHuman string `bson:"release_human"`
+}
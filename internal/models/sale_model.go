@@ -1,10 +1,25 @@
 package models
 
+import (
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+// Prices stay structured through the pipeline and are formatted only when rendered
 type Sale struct {
-	Name         string `json:"name"`
-	Url          string `json:"url"`
-	Image        string `json:"image"`
-	Discount     string `json:"discount"`
-	InitialPrice string `json:"initial_price"`
-	FinalPrice   string `json:"final_price"`
+	Slug       string `json:"slug"`
+	SteamAppId uint64 `json:"steam_app_id"`
+	Name       string `json:"name"`
+	Edition    string `json:"edition"`
+	Url        string `json:"url"`
+	Image      string `json:"image"`
+	// Store blurb of the app on sale, empty for packages and bundles
+	Description     string      `json:"description"`
+	DiscountPercent int         `json:"discount_percent"`
+	InitialPrice    types.Money `json:"initial_price"`
+	FinalPrice      types.Money `json:"final_price"`
+	// Free to keep for a limited time
+	Giveaway bool `json:"giveaway"`
+	// Empty for packages, bundles and apps without reviews
+	Reviews steam.ReviewSummary `json:"reviews"`
 }
@@ -0,0 +1,6 @@
+package models
+
+type ScrapedGame struct {
+	Slug  string
+	Title string
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	SlugMatchStatusMatched   = "matched"
+	SlugMatchStatusAmbiguous = "ambiguous"
+	SlugMatchStatusUnmatched = "unmatched"
+	// User confirmed none of the candidates fit
+	SlugMatchStatusRejected = "rejected"
+)
+
+type SlugMatchCandidate struct {
+	IgdbId uint64  `bson:"igdb_id"`
+	Name   string  `bson:"name"`
+	Score  float64 `bson:"score"`
+}
+
+// Links a Backloggd slug igdb does not know to an igdb game found by name
+type SlugMatch struct {
+	Id              bson.ObjectID        `bson:"_id,omitempty"`
+	BackloggdSlug   string               `bson:"backloggd_slug"`
+	Title           string               `bson:"title"`
+	Status          string               `bson:"status"`
+	IgdbId          uint64               `bson:"igdb_id"`
+	Candidates      []SlugMatchCandidate `bson:"candidates"`
+	NotifiedUserIds []int64              `bson:"notified_user_ids"`
+	CheckedAt       time.Time            `bson:"checked_at"`
+}
@@ -1,6 +1,18 @@
 package steam
 
+type Availability string
+
+const (
+	AvailabilityPriced     Availability = "priced"
+	AvailabilityFree       Availability = "free"
+	AvailabilityComingSoon Availability = "coming_soon"
+	AvailabilityGiveaway   Availability = "giveaway"
+	// Not sold in the country prices were requested for
+	AvailabilityUnavailable Availability = "unavailable"
+)
+
 type priceOverview struct {
+	Currency         string `json:"currency" bson:"currency"`
 	DiscountPercent  int    `json:"discount_percent" bson:"discount_percent"`
 	Initial          int    `json:"initial" bson:"initial"`
 	InitialFormatted string `json:"initial_formatted" bson:"initial_formatted"`
@@ -8,8 +20,52 @@ type priceOverview struct {
 	FinalFormatted   string `json:"final_formatted" bson:"final_formatted"`
 }
 
+type releaseDate struct {
+	ComingSoon bool   `json:"coming_soon" bson:"coming_soon"`
+	Date       string `json:"date" bson:"date"`
+}
+
 type AppDetails struct {
-	Name          string        `json:"name" bson:"name"`
-	SteamAppId    uint64        `json:"steam_appid" bson:"steam_appid"`
-	PriceOverview priceOverview `json:"price_overview" bson:"price_overview"`
+	Type        string `json:"type" bson:"type"`
+	Name        string `json:"name" bson:"name"`
+	SteamAppId  uint64 `json:"steam_appid" bson:"steam_appid"`
+	HeaderImage string `json:"header_image" bson:"header_image"`
+	// Store blurb, shown under the deal in digests
+	ShortDescription string      `json:"short_description" bson:"short_description"`
+	IsFree           bool        `json:"is_free" bson:"is_free"`
+	ReleaseDate      releaseDate `json:"release_date" bson:"release_date"`
+	// Missing for free, unreleased and region locked apps
+	PriceOverview *priceOverview `json:"price_overview,omitempty" bson:"price_overview,omitempty"`
+	// Steam answers with success false for apps it does not sell in the country
+	Unavailable bool `json:"-" bson:"unavailable"`
+	// Prices differ per region, so details are cached per country
+	CountryCode string `json:"-" bson:"country_code"`
+}
+
+// Soundtracks, demos and trailers share igdb links with the game, but are not editions of it
+func (a AppDetails) IsEdition() bool {
+	switch a.Type {
+	case "music", "demo", "video", "advertising", "hardware":
+		return false
+	default:
+		return true
+	}
+}
+
+func (a AppDetails) GetAvailability() Availability {
+	switch {
+	case a.Unavailable:
+		return AvailabilityUnavailable
+	case a.PriceOverview != nil && a.PriceOverview.Final == 0 && a.PriceOverview.DiscountPercent == 100:
+		return AvailabilityGiveaway
+	case a.PriceOverview != nil:
+		return AvailabilityPriced
+	case a.IsFree:
+		return AvailabilityFree
+	case a.ReleaseDate.ComingSoon:
+		return AvailabilityComingSoon
+	default:
+		// Released paid app without a price can not be bought here
+		return AvailabilityUnavailable
+	}
 }
@@ -0,0 +1,26 @@
+package steam
+
+import "testing"
+
+func TestAppDetailsGetAvailability(t *testing.T) {
+	tests := []struct {
+		name       string
+		appDetails AppDetails
+		want       Availability
+	}{
+		{name: "priced", appDetails: AppDetails{PriceOverview: &priceOverview{Initial: 1499, Final: 749, DiscountPercent: 50}}, want: AvailabilityPriced},
+		{name: "given away", appDetails: AppDetails{PriceOverview: &priceOverview{Initial: 1499, Final: 0, DiscountPercent: 100}}, want: AvailabilityGiveaway},
+		{name: "free to play", appDetails: AppDetails{IsFree: true}, want: AvailabilityFree},
+		{name: "coming soon", appDetails: AppDetails{ReleaseDate: releaseDate{ComingSoon: true}}, want: AvailabilityComingSoon},
+		{name: "not sold in the country", appDetails: AppDetails{Unavailable: true, PriceOverview: &priceOverview{Final: 999}}, want: AvailabilityUnavailable},
+		{name: "released paid app without price", appDetails: AppDetails{}, want: AvailabilityUnavailable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.appDetails.GetAvailability(); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
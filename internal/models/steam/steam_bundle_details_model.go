@@ -0,0 +1,12 @@
+package steam
+
+type BundleDetails struct {
+	BundleId         uint64 `json:"bundleid"`
+	Name             string `json:"name"`
+	HeaderImage      string `json:"header_image_url"`
+	DiscountPercent  int    `json:"discount_percent"`
+	InitialPrice     int    `json:"initial_price"`
+	InitialFormatted string `json:"formatted_orig_price"`
+	FinalPrice       int    `json:"final_price"`
+	FinalFormatted   string `json:"formatted_final_price"`
+}
@@ -0,0 +1,54 @@
+package steam
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Kinds of things Steam sells, named after their store page paths
+type ItemKind string
+
+const (
+	ItemApp    ItemKind = "app"
+	ItemSub    ItemKind = "sub"
+	ItemBundle ItemKind = "bundle"
+)
+
+type ItemId struct {
+	Kind ItemKind
+	Id   uint64
+}
+
+// Apps are stored as bare ids to stay compatible with igdb uids, packages and bundles as "sub/<id>" and "bundle/<id>"
+func ParseItemId(uid string) (ItemId, error) {
+	kind := ItemApp
+	if prefix, id, isFound := strings.Cut(uid, "/"); isFound {
+		kind = ItemKind(prefix)
+		uid = id
+	}
+
+	if kind != ItemApp && kind != ItemSub && kind != ItemBundle {
+		return ItemId{}, fmt.Errorf("unknown steam item kind: %s", kind)
+	}
+
+	id, err := strconv.ParseUint(uid, 10, 64)
+	if err != nil || id == 0 {
+		return ItemId{}, errors.New("malformed steam item id: " + uid)
+	}
+
+	return ItemId{Kind: kind, Id: id}, nil
+}
+
+func (i ItemId) Uid() string {
+	if i.Kind == ItemApp {
+		return strconv.FormatUint(i.Id, 10)
+	}
+
+	return fmt.Sprintf("%s/%d", i.Kind, i.Id)
+}
+
+func (i ItemId) GetStoreUrl() string {
+	return fmt.Sprintf("https://store.steampowered.com/%s/%d/", i.Kind, i.Id)
+}
@@ -0,0 +1,41 @@
+package steam
+
+import "testing"
+
+func TestParseItemId(t *testing.T) {
+	tests := []struct {
+		name    string
+		uid     string
+		want    ItemId
+		wantErr bool
+	}{
+		{name: "bare app id", uid: "367520", want: ItemId{Kind: ItemApp, Id: 367520}},
+		{name: "package", uid: "sub/469", want: ItemId{Kind: ItemSub, Id: 469}},
+		{name: "bundle", uid: "bundle/232", want: ItemId{Kind: ItemBundle, Id: 232}},
+		{name: "explicit app", uid: "app/620", want: ItemId{Kind: ItemApp, Id: 620}},
+		{name: "unknown kind", uid: "dlc/1", wantErr: true},
+		{name: "zero id", uid: "0", wantErr: true},
+		{name: "not a number", uid: "sub/abc", wantErr: true},
+		{name: "empty", uid: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseItemId(test.uid)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got %+v, want an error", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != test.want {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
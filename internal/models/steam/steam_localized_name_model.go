@@ -0,0 +1,12 @@
+package steam
+
+import "time"
+
+// Store name and description of an app in one language, kept apart from prices which are shared by all languages
+type LocalizedName struct {
+	SteamAppId  uint64    `bson:"steam_appid"`
+	Language    string    `bson:"language"`
+	Name        string    `bson:"name"`
+	Description string    `bson:"description"`
+	CheckedAt   time.Time `bson:"checked_at"`
+}
@@ -0,0 +1,16 @@
+package steam
+
+type packagePrice struct {
+	Currency        string `json:"currency"`
+	Initial         int    `json:"initial"`
+	Final           int    `json:"final"`
+	DiscountPercent int    `json:"discount_percent"`
+}
+
+// Steam calls them packages in the api and subs on the store
+type PackageDetails struct {
+	PackageId   uint64       `json:"-"`
+	Name        string       `json:"name"`
+	HeaderImage string       `json:"header_image"`
+	Price       packagePrice `json:"price"`
+}
@@ -0,0 +1,20 @@
+package steam
+
+import "time"
+
+// Steam user reviews in all languages, as shown on the store page
+type ReviewSummary struct {
+	SteamAppId       uint64    `json:"-" bson:"steam_appid"`
+	ScoreDescription string    `json:"review_score_desc" bson:"review_score_desc"`
+	TotalPositive    int       `json:"total_positive" bson:"total_positive"`
+	TotalReviews     int       `json:"total_reviews" bson:"total_reviews"`
+	CheckedAt        time.Time `json:"-" bson:"checked_at"`
+}
+
+func (r ReviewSummary) GetPositivePercent() int {
+	if r.TotalReviews == 0 {
+		return 0
+	}
+
+	return r.TotalPositive * 100 / r.TotalReviews
+}
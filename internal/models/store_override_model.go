@@ -0,0 +1,15 @@
+package models
+
+import "github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+
+// User id of overrides that apply to everyone
+const GlobalOverrideUserId = 0
+
+// Pins a game slug to specific store ids or excludes the store for it
+type StoreOverride struct {
+	UserId   int64      `bson:"user_id"`
+	Slug     string     `bson:"slug"`
+	Store    igdb.Store `bson:"store"`
+	Uids     []string   `bson:"uids"`
+	Excluded bool       `bson:"excluded"`
+}
@@ -0,0 +1,6 @@
+package models
+
+type TelegramFile struct {
+	Url    string `bson:"url"`
+	FileId string `bson:"file_id"`
+}
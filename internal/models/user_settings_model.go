@@ -1,8 +1,24 @@
 package models
 
+const (
+	WishlistSourceBackloggd = "backloggd"
+	WishlistSourceSteam     = "steam"
+)
+
 type UserSettings struct {
 	UserId           int64  `bson:"user_id"`
+	WishlistSource   string `bson:"wishlist_source"`
 	BackloggdProfile string `bson:"backloggd_profile"`
+	SteamProfile     string `bson:"steam_profile"`
 	CountryCode      string `bson:"country_code"`
 	CurrencyCode     string `bson:"currency_code"`
+	// Prices are also shown converted to it when set
+	DisplayCurrency       string      `bson:"display_currency"`
+	NotifyWishlistChanges bool        `bson:"notify_wishlist_changes"`
+	Filters               GameFilters `bson:"filters"`
+	CompareRegions        bool        `bson:"compare_regions"`
+	// Regions the digest compares deals in, empty means every region from COMPARE_COUNTRIES
+	CompareCountries []string `bson:"compare_countries"`
+	// Language code Steam names are shown in, the bot itself only speaks English. Empty means English names
+	Language string `bson:"language"`
 }
@@ -0,0 +1,8 @@
+package models
+
+type WatchlistImportRow struct {
+	Line       int
+	Title      string
+	Slug       string
+	SteamAppId uint64
+}
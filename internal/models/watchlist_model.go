@@ -0,0 +1,10 @@
+package models
+
+// Entries are identified by igdb id, steam-only entries leave it zero and carry steam app id instead
+type WatchlistEntry struct {
+	UserId     int64  `bson:"user_id"`
+	IgdbId     uint64 `bson:"igdb_id"`
+	SteamAppId uint64 `bson:"steam_app_id"`
+	Slug       string `bson:"slug"`
+	Name       string `bson:"name"`
+}
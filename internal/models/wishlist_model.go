@@ -1,6 +1,7 @@
 package models
 
 type Wishlist struct {
-	UserId   int64    `bson:"user_id"`
-	SlugList []string `bson:"slug_list"`
+	UserId      int64    `bson:"user_id"`
+	SlugList    []string `bson:"slug_list"`
+	SteamAppIds []uint64 `bson:"steam_app_ids"`
 }
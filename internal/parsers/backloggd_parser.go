@@ -1,44 +1,91 @@
 package parsers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
 	"github.com/theverysameliquidsnake/sales-bot/internal/types"
 )
 
-func ParseBackloggdWishlist(profileUrl string) ([]string, error) {
+// Name the monitor reports Backloggd under
+const BackloggdWishlistUpstream = "backloggd wishlist"
+
+// Backloggd has no public api, pages are scraped politely
+const backloggdRequestsPerSecond = 1
+
+// Selectors the parser relies on, losing any of them means Backloggd changed its markup
+const (
+	backloggdGamesLinkSelector    = "a[href^='/u/'][href$='/games/']"
+	backloggdWishlistLinkSelector = "a[href^='/u/'][href$='/type:wishlist/']"
+	backloggdGameListSelector     = "div[id='game-lists']"
+)
+
+type BackloggdApi interface {
+	ParseWishlist(ctx context.Context, profileUrl string) ([]models.ScrapedGame, error)
+}
+
+type BackloggdParser struct {
+	baseUrl    string
+	httpClient *http.Client
+	limiter    *types.RateLimiter
+	monitor    *types.UpstreamMonitor
+}
+
+func NewBackloggdParser(baseUrl string, limiters *types.HostRateLimiters, monitor *types.UpstreamMonitor) *BackloggdParser {
+	return &BackloggdParser{
+		baseUrl:    baseUrl,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		limiter:    limiters.Get(baseUrl, backloggdRequestsPerSecond),
+		monitor:    monitor,
+	}
+}
+
+func (p *BackloggdParser) ParseWishlist(ctx context.Context, profileUrl string) ([]models.ScrapedGame, error) {
+	games, err := p.parseWishlist(ctx, profileUrl)
+	if ctx.Err() == nil {
+		p.monitor.RecordResult(BackloggdWishlistUpstream, err == nil)
+	}
+
+	return games, err
+}
+
+func (p *BackloggdParser) parseWishlist(ctx context.Context, profileUrl string) ([]models.ScrapedGame, error) {
 	// Obtain wishlist link
-	doc, err := getGoqueryDoc(profileUrl)
+	doc, err := p.getGoqueryDoc(ctx, profileUrl)
 	if err != nil {
 		return nil, errors.Join(fmt.Errorf("parser: could not get response from: %s", profileUrl), err)
 	}
 
-	gamesUrl, isExists := doc.Find("a[href^='/u/'][href$='/games/']").Attr("href")
+	gamesUrl, isExists := doc.Find(backloggdGamesLinkSelector).Attr("href")
 	if !isExists {
+		p.reportMissingSelector(backloggdGamesLinkSelector, doc)
 		return nil, fmt.Errorf("parser: could not find games url: %s", profileUrl)
 	}
 
-	gamesUrl, err = resolvePartialUrl(gamesUrl)
+	gamesUrl, err = p.resolvePartialUrl(gamesUrl)
 	if err != nil {
 		return nil, errors.Join(fmt.Errorf("parser: could not resolve partial url: %s", gamesUrl), err)
 	}
 
-	doc, err = getGoqueryDoc(gamesUrl)
+	doc, err = p.getGoqueryDoc(ctx, gamesUrl)
 	if err != nil {
 		return nil, errors.Join(fmt.Errorf("parser: could not get response from: %s", gamesUrl), err)
 	}
 
-	wishlistUrl, isExists := doc.Find("a[href^='/u/'][href$='/type:wishlist/']").Attr("href")
+	wishlistUrl, isExists := doc.Find(backloggdWishlistLinkSelector).Attr("href")
 	if !isExists {
+		p.reportMissingSelector(backloggdWishlistLinkSelector, doc)
 		return nil, fmt.Errorf("parser: could not find wishlist url: %s", gamesUrl)
 	}
 
-	wishlistUrl, err = resolvePartialUrl(wishlistUrl)
+	wishlistUrl, err = p.resolvePartialUrl(wishlistUrl)
 	if err != nil {
 		return nil, errors.Join(fmt.Errorf("parser: could not resolve partial url: %s", wishlistUrl), err)
 	}
@@ -47,7 +94,7 @@ func ParseBackloggdWishlist(profileUrl string) ([]string, error) {
 	pagesUrl := types.NewSet()
 	//pagesUrl.Add(wishlistUrl)
 
-	doc, err = getGoqueryDoc(wishlistUrl)
+	doc, err = p.getGoqueryDoc(ctx, wishlistUrl)
 	if err != nil {
 		return nil, errors.Join(fmt.Errorf("parser: could not get response from: %s", wishlistUrl), err)
 	}
@@ -59,40 +106,82 @@ func ParseBackloggdWishlist(profileUrl string) ([]string, error) {
 		}
 	})
 
-	// Parse each page and collect game slugs
+	// Empty wishlists still have the list container
+	if doc.Find(backloggdGameListSelector).Length() == 0 {
+		p.reportMissingSelector(backloggdGameListSelector, doc)
+		return nil, fmt.Errorf("parser: could not find game list: %s", wishlistUrl)
+	}
+
+	// Parse each page and collect game slugs with titles
 	slugs := types.NewSet()
+	titles := make(map[string]string)
 
-	doc.Find("div[id='game-lists'] a[href^='/games/']").Each(func(i int, s *goquery.Selection) {
-		gameUrl, isExists := s.Attr("href")
-		if isExists {
-			slugs.Add(strings.Split(gameUrl, "/")[2])
-		}
-	})
+	collectScrapedGames(doc, slugs, titles)
 
 	for _, pageUrl := range pagesUrl.Values() {
-		pageUrl, err = resolvePartialUrl(wishlistUrl)
+		pageUrl, err = p.resolvePartialUrl(pageUrl)
 		if err != nil {
 			return nil, errors.Join(fmt.Errorf("parser: could not resolve partial url: %s", pageUrl), err)
 		}
 
-		doc, err = getGoqueryDoc(pageUrl)
+		doc, err = p.getGoqueryDoc(ctx, pageUrl)
 		if err != nil {
 			return nil, errors.Join(fmt.Errorf("parser: could not get response from: %s", pageUrl), err)
 		}
 
-		doc.Find("div[id='game-lists'] a[href^='/games/']").Each(func(i int, s *goquery.Selection) {
-			gameUrl, isExists := s.Attr("href")
-			if isExists {
-				slugs.Add(strings.Split(gameUrl, "/")[2])
-			}
-		})
+		collectScrapedGames(doc, slugs, titles)
+	}
+
+	var games []models.ScrapedGame
+	for _, slug := range slugs.Values() {
+		games = append(games, models.ScrapedGame{Slug: slug, Title: titles[slug]})
 	}
 
-	return slugs.Values(), nil
+	return games, nil
 }
 
-func getGoqueryDoc(url string) (*goquery.Document, error) {
-	res, err := http.Get(url)
+// Title comes from cover alt text, falls back to link text
+func collectScrapedGames(doc *goquery.Document, slugs *types.Set, titles map[string]string) {
+	doc.Find(backloggdGameListSelector + " a[href^='/games/']").Each(func(i int, s *goquery.Selection) {
+		gameUrl, isExists := s.Attr("href")
+		if !isExists {
+			return
+		}
+
+		slug := strings.Split(gameUrl, "/")[2]
+		slugs.Add(slug)
+
+		title := strings.TrimSpace(s.Find("img").AttrOr("alt", ""))
+		if title == "" {
+			title = strings.TrimSpace(s.Text())
+		}
+
+		if title != "" && titles[slug] == "" {
+			titles[slug] = title
+		}
+	})
+}
+
+func (p *BackloggdParser) reportMissingSelector(selector string, doc *goquery.Document) {
+	sample, err := doc.Html()
+	if err != nil {
+		sample = err.Error()
+	}
+
+	p.monitor.ReportDrift(BackloggdWishlistUpstream, "missing selector: "+selector, sample)
+}
+
+func (p *BackloggdParser) getGoqueryDoc(ctx context.Context, url string) (*goquery.Document, error) {
+	if err := p.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := p.httpClient.Do(request)
 	if err != nil {
 		return nil, err
 	}
@@ -110,8 +199,8 @@ func getGoqueryDoc(url string) (*goquery.Document, error) {
 	return doc, nil
 }
 
-func resolvePartialUrl(partialUrl string) (string, error) {
-	fullUrl, err := url.JoinPath("https://backloggd.com/", partialUrl)
+func (p *BackloggdParser) resolvePartialUrl(partialUrl string) (string, error) {
+	fullUrl, err := url.JoinPath(p.baseUrl+"/", partialUrl)
 	if err != nil {
 		return "", err
 	}
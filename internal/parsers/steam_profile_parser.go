@@ -0,0 +1,115 @@
+package parsers
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+var steamId64Regexp = regexp.MustCompile(`^7656\d{13}$`)
+
+const steamCommunityRequestsPerSecond = 1
+
+type steamProfileXml struct {
+	SteamId64 string `xml:"steamID64"`
+	Error     string `xml:"error"`
+}
+
+type SteamProfileApi interface {
+	ParseSteamId64(ctx context.Context, profile string) (string, error)
+}
+
+type SteamProfileParser struct {
+	communityBaseUrl string
+	httpClient       *http.Client
+	limiter          *types.RateLimiter
+}
+
+func NewSteamProfileParser(communityBaseUrl string, limiters *types.HostRateLimiters) *SteamProfileParser {
+	return &SteamProfileParser{
+		communityBaseUrl: communityBaseUrl,
+		httpClient:       &http.Client{Timeout: 30 * time.Second},
+		limiter:          limiters.Get(communityBaseUrl, steamCommunityRequestsPerSecond),
+	}
+}
+
+// Accepts SteamID64, vanity name or full steamcommunity.com profile url
+func (p *SteamProfileParser) ParseSteamId64(ctx context.Context, profile string) (string, error) {
+	profile = strings.TrimSuffix(strings.TrimSpace(profile), "/")
+
+	if steamId64Regexp.MatchString(profile) {
+		return profile, nil
+	}
+
+	vanityName := profile
+	if strings.HasPrefix(profile, "http://") || strings.HasPrefix(profile, "https://") {
+		profileUrl, err := url.Parse(profile)
+		if err != nil {
+			return "", errors.Join(fmt.Errorf("parser: could not parse steam profile url: %s", profile), err)
+		}
+
+		if profileUrl.Host != "steamcommunity.com" && !strings.HasPrefix(profile, p.communityBaseUrl) {
+			return "", fmt.Errorf("parser: not a steam community url: %s", profile)
+		}
+
+		pathParts := strings.Split(strings.Trim(profileUrl.Path, "/"), "/")
+		if len(pathParts) < 2 {
+			return "", fmt.Errorf("parser: could not find steam profile in url: %s", profile)
+		}
+
+		switch pathParts[0] {
+		case "profiles":
+			if !steamId64Regexp.MatchString(pathParts[1]) {
+				return "", fmt.Errorf("parser: not a steam id: %s", pathParts[1])
+			}
+
+			return pathParts[1], nil
+		case "id":
+			vanityName = pathParts[1]
+		default:
+			return "", fmt.Errorf("parser: could not find steam profile in url: %s", profile)
+		}
+	}
+
+	return p.resolveSteamVanityName(ctx, vanityName)
+}
+
+func (p *SteamProfileParser) resolveSteamVanityName(ctx context.Context, vanityName string) (string, error) {
+	if err := p.limiter.Wait(ctx); err != nil {
+		return "", errors.Join(fmt.Errorf("parser: gave up waiting for steam profile: %s", vanityName), err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/id/%s/?xml=1", p.communityBaseUrl, url.PathEscape(vanityName)), nil)
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("parser: could not create steam profile request: %s", vanityName), err)
+	}
+
+	res, err := p.httpClient.Do(request)
+	if err != nil {
+		return "", errors.Join(fmt.Errorf("parser: could not get steam profile: %s", vanityName), err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		return "", fmt.Errorf("parser: request error: %d %s", res.StatusCode, vanityName)
+	}
+
+	var profileXml steamProfileXml
+	if err = xml.NewDecoder(res.Body).Decode(&profileXml); err != nil {
+		return "", errors.Join(fmt.Errorf("parser: could not decode steam profile: %s", vanityName), err)
+	}
+
+	if profileXml.Error != "" || !steamId64Regexp.MatchString(profileXml.SteamId64) {
+		return "", fmt.Errorf("parser: could not resolve steam vanity name: %s", vanityName)
+	}
+
+	return profileXml.SteamId64, nil
+}
@@ -0,0 +1,185 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+)
+
+const maxWatchlistImportRows = 500
+
+var (
+	slugRegexp       = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	steamAppIdRegexp = regexp.MustCompile(`^\d+$`)
+)
+
+type watchlistImportJsonRow struct {
+	Title      string `json:"title"`
+	Name       string `json:"name"`
+	Slug       string `json:"slug"`
+	SteamAppId uint64 `json:"steam_app_id"`
+	AppId      uint64 `json:"appid"`
+}
+
+// Accepts csv with optional title/name, slug and steam_app_id/appid header or json array of strings or objects
+func ParseWatchlistImport(fileName string, data []byte) ([]models.WatchlistImportRow, error) {
+	var rows []models.WatchlistImportRow
+	var err error
+
+	if strings.HasSuffix(strings.ToLower(fileName), ".json") {
+		rows, err = parseWatchlistImportJson(data)
+	} else {
+		rows, err = parseWatchlistImportCsv(data)
+	}
+
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("parser: could not parse watchlist import: %s", fileName), err)
+	}
+
+	if len(rows) > maxWatchlistImportRows {
+		return nil, fmt.Errorf("parser: too many rows in watchlist import: %d, at most %d allowed", len(rows), maxWatchlistImportRows)
+	}
+
+	return rows, nil
+}
+
+func parseWatchlistImportCsv(data []byte) ([]models.WatchlistImportRow, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	// Column index by kind, -1 means a single column with values of any kind
+	titleColumn, slugColumn, steamAppIdColumn := -1, -1, -1
+	isHeaderChecked := false
+
+	var rows []models.WatchlistImportRow
+	line := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line++
+
+		if !isHeaderChecked {
+			isHeaderChecked = true
+
+			isHeader := false
+			for i, column := range record {
+				switch strings.ToLower(strings.TrimSpace(column)) {
+				case "title", "name":
+					titleColumn, isHeader = i, true
+				case "slug":
+					slugColumn, isHeader = i, true
+				case "steam_app_id", "appid", "app_id":
+					steamAppIdColumn, isHeader = i, true
+				}
+			}
+
+			if isHeader {
+				continue
+			}
+		}
+
+		var row models.WatchlistImportRow
+		if titleColumn == -1 && slugColumn == -1 && steamAppIdColumn == -1 {
+			if len(record) == 0 {
+				continue
+			}
+
+			row = classifyWatchlistImportValue(record[0])
+		} else {
+			row.Title = getCsvColumn(record, titleColumn)
+			row.Slug = getCsvColumn(record, slugColumn)
+			if steamAppId := getCsvColumn(record, steamAppIdColumn); steamAppIdRegexp.MatchString(steamAppId) {
+				row.SteamAppId, _ = strconv.ParseUint(steamAppId, 10, 64)
+			}
+		}
+
+		if row.Title == "" && row.Slug == "" && row.SteamAppId == 0 {
+			continue
+		}
+
+		row.Line = line
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseWatchlistImportJson(data []byte) ([]models.WatchlistImportRow, error) {
+	var rawRows []json.RawMessage
+	if err := json.Unmarshal(data, &rawRows); err != nil {
+		return nil, err
+	}
+
+	var rows []models.WatchlistImportRow
+	for i, rawRow := range rawRows {
+		var row models.WatchlistImportRow
+
+		var value string
+		if err := json.Unmarshal(rawRow, &value); err == nil {
+			row = classifyWatchlistImportValue(value)
+		} else {
+			var jsonRow watchlistImportJsonRow
+			if err := json.Unmarshal(rawRow, &jsonRow); err != nil {
+				return nil, errors.Join(fmt.Errorf("could not parse row: %d", i+1), err)
+			}
+
+			row.Title = strings.TrimSpace(jsonRow.Title)
+			if row.Title == "" {
+				row.Title = strings.TrimSpace(jsonRow.Name)
+			}
+			row.Slug = strings.TrimSpace(jsonRow.Slug)
+			row.SteamAppId = jsonRow.SteamAppId
+			if row.SteamAppId == 0 {
+				row.SteamAppId = jsonRow.AppId
+			}
+		}
+
+		if row.Title == "" && row.Slug == "" && row.SteamAppId == 0 {
+			continue
+		}
+
+		row.Line = i + 1
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// Guesses whether a bare value is a steam app id, a slug or a title
+func classifyWatchlistImportValue(value string) models.WatchlistImportRow {
+	value = strings.TrimSpace(value)
+
+	if steamAppIdRegexp.MatchString(value) {
+		if steamAppId, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return models.WatchlistImportRow{SteamAppId: steamAppId}
+		}
+	}
+
+	if slugRegexp.MatchString(value) && strings.Contains(value, "-") {
+		return models.WatchlistImportRow{Slug: value}
+	}
+
+	return models.WatchlistImportRow{Title: value}
+}
+
+func getCsvColumn(record []string, column int) string {
+	if column < 0 || column >= len(record) {
+		return ""
+	}
+
+	return strings.TrimSpace(record[column])
+}
@@ -0,0 +1,87 @@
+package parsers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+)
+
+func TestParseWatchlistImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		data     string
+		want     []models.WatchlistImportRow
+		wantErr  bool
+	}{
+		{
+			name:     "bare values are classified",
+			fileName: "games.txt",
+			data:     "367520\nhollow-knight\nPortal 2\n",
+			want: []models.WatchlistImportRow{
+				{Line: 1, SteamAppId: 367520},
+				{Line: 2, Slug: "hollow-knight"},
+				{Line: 3, Title: "Portal 2"},
+			},
+		},
+		{
+			name:     "single word is a title, not a slug",
+			fileName: "games.csv",
+			data:     "celeste\n",
+			want:     []models.WatchlistImportRow{{Line: 1, Title: "celeste"}},
+		},
+		{
+			name:     "header picks columns and keeps line numbers",
+			fileName: "games.csv",
+			data:     "name,appid\nHollow Knight,367520\n,\nPortal 2,not-a-number\n",
+			want: []models.WatchlistImportRow{
+				{Line: 2, Title: "Hollow Knight", SteamAppId: 367520},
+				{Line: 4, Title: "Portal 2"},
+			},
+		},
+		{
+			name:     "json strings and objects",
+			fileName: "games.JSON",
+			data:     `["hollow-knight", {"name": " Portal 2 "}, {"appid": 620}, {}]`,
+			want: []models.WatchlistImportRow{
+				{Line: 1, Slug: "hollow-knight"},
+				{Line: 2, Title: "Portal 2"},
+				{Line: 3, SteamAppId: 620},
+			},
+		},
+		{
+			name:     "json that is not an array",
+			fileName: "games.json",
+			data:     `{"title": "Portal 2"}`,
+			wantErr:  true,
+		},
+		{
+			name:     "too many rows",
+			fileName: "games.txt",
+			data:     strings.Repeat("Portal 2\n", maxWatchlistImportRows+1),
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ParseWatchlistImport(test.fileName, []byte(test.data))
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("got rows %+v, want an error", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package repos
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func GetReleaseWatches(userId int64) ([]models.ReleaseWatch, error) {
+	filter := bson.D{{Key: "user_id", Value: userId}}
+
+	cursor, err := getReleaseWatchesCollection().Find(context.Background(), filter)
+	if err != nil {
+		return nil, errors.Join(errors.New("repository: could not query release watches:"), err)
+	}
+	defer cursor.Close(context.Background())
+
+	var results []models.ReleaseWatch
+	if err = cursor.All(context.Background(), &results); err != nil {
+		return nil, errors.Join(errors.New("repository: could not map release watches:"), err)
+	}
+
+	return results, nil
+}
+
+func UpsertReleaseWatch(watch models.ReleaseWatch) error {
+	filter := bson.D{{Key: "user_id", Value: watch.UserId}, {Key: "slug", Value: watch.Slug}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: watch.Name}, {Key: "release_date", Value: watch.ReleaseDate}, {Key: "release_human", Value: watch.ReleaseHuman}}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getReleaseWatchesCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update release watch:"), err)
+	}
+
+	return nil
+}
+
+func DeleteReleaseWatch(userId int64, slug string) error {
+	filter := bson.D{{Key: "user_id", Value: userId}, {Key: "slug", Value: slug}}
+
+	if _, err := getReleaseWatchesCollection().DeleteOne(context.Background(), filter); err != nil {
+		return errors.Join(errors.New("repository: could not delete release watch:"), err)
+	}
+
+	return nil
+}
+
+func getReleaseWatchesCollection() *mongo.Collection {
+	return configs.GetMongoDatabase().Collection("release_watches")
+}
@@ -0,0 +1,101 @@
+package repos
+
+import (
+	"time"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+)
+
+// Collections scheduled runs read and write, so the pipeline can run against an in-memory store
+type RunStore interface {
+	GetWishlist(userId int64) (*models.Wishlist, error)
+	UpsertWishlist(wishlist models.Wishlist) error
+	GetWatchlist(userId int64) ([]models.WatchlistEntry, error)
+	GetIgdbGames(slugs []string) ([]igdb.Game, error)
+	InsertIgdbGames(games []igdb.Game) error
+	GetSlugMatches(backloggdSlugs []string) ([]models.SlugMatch, error)
+	UpsertSlugMatch(match models.SlugMatch) (*models.SlugMatch, error)
+	GetStoreOverrides(userId int64, slugs []string) ([]models.StoreOverride, error)
+	GetReleaseWatches(userId int64) ([]models.ReleaseWatch, error)
+	UpsertReleaseWatch(watch models.ReleaseWatch) error
+	DeleteReleaseWatch(userId int64, slug string) error
+	GetSteamAppsDetails(appIds []uint64, countryCode string) ([]steam.AppDetails, error)
+	InsertSteamAppsDetails(steamAppsDetails []steam.AppDetails) error
+	GetSteamReviewSummaries(steamAppsIds []uint64, checkedAfter time.Time) ([]steam.ReviewSummary, error)
+	UpsertSteamReviewSummary(reviewSummary steam.ReviewSummary) error
+	GetSteamLocalizedNames(steamAppsIds []uint64, language string, checkedAfter time.Time) ([]steam.LocalizedName, error)
+	UpsertSteamLocalizedName(localizedName steam.LocalizedName) error
+}
+
+// Run store backed by the functions of this package
+type MongoRunStore struct{}
+
+func (MongoRunStore) GetWishlist(userId int64) (*models.Wishlist, error) {
+	return GetWishlist(userId)
+}
+
+func (MongoRunStore) UpsertWishlist(wishlist models.Wishlist) error {
+	return UpsertWishlist(wishlist)
+}
+
+func (MongoRunStore) GetWatchlist(userId int64) ([]models.WatchlistEntry, error) {
+	return GetWatchlist(userId)
+}
+
+func (MongoRunStore) GetIgdbGames(slugs []string) ([]igdb.Game, error) {
+	return GetIgdbGames(slugs)
+}
+
+func (MongoRunStore) InsertIgdbGames(games []igdb.Game) error {
+	return InsertIgdbGames(games)
+}
+
+func (MongoRunStore) GetSlugMatches(backloggdSlugs []string) ([]models.SlugMatch, error) {
+	return GetSlugMatches(backloggdSlugs)
+}
+
+func (MongoRunStore) UpsertSlugMatch(match models.SlugMatch) (*models.SlugMatch, error) {
+	return UpsertSlugMatch(match)
+}
+
+func (MongoRunStore) GetStoreOverrides(userId int64, slugs []string) ([]models.StoreOverride, error) {
+	return GetStoreOverrides(userId, slugs)
+}
+
+func (MongoRunStore) GetReleaseWatches(userId int64) ([]models.ReleaseWatch, error) {
+	return GetReleaseWatches(userId)
+}
+
+func (MongoRunStore) UpsertReleaseWatch(watch models.ReleaseWatch) error {
+	return UpsertReleaseWatch(watch)
+}
+
+func (MongoRunStore) DeleteReleaseWatch(userId int64, slug string) error {
+	return DeleteReleaseWatch(userId, slug)
+}
+
+func (MongoRunStore) GetSteamAppsDetails(appIds []uint64, countryCode string) ([]steam.AppDetails, error) {
+	return GetSteamAppsDetails(appIds, countryCode)
+}
+
+func (MongoRunStore) InsertSteamAppsDetails(steamAppsDetails []steam.AppDetails) error {
+	return InsertSteamAppsDetails(steamAppsDetails)
+}
+
+func (MongoRunStore) GetSteamReviewSummaries(steamAppsIds []uint64, checkedAfter time.Time) ([]steam.ReviewSummary, error) {
+	return GetSteamReviewSummaries(steamAppsIds, checkedAfter)
+}
+
+func (MongoRunStore) UpsertSteamReviewSummary(reviewSummary steam.ReviewSummary) error {
+	return UpsertSteamReviewSummary(reviewSummary)
+}
+
+func (MongoRunStore) GetSteamLocalizedNames(steamAppsIds []uint64, language string, checkedAfter time.Time) ([]steam.LocalizedName, error) {
+	return GetSteamLocalizedNames(steamAppsIds, language, checkedAfter)
+}
+
+func (MongoRunStore) UpsertSteamLocalizedName(localizedName steam.LocalizedName) error {
+	return UpsertSteamLocalizedName(localizedName)
+}
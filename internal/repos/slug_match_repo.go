@@ -0,0 +1,91 @@
+package repos
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func GetSlugMatches(backloggdSlugs []string) ([]models.SlugMatch, error) {
+	filter := bson.M{"backloggd_slug": bson.M{"$in": backloggdSlugs}}
+
+	cursor, err := getSlugMatchesCollection().Find(context.Background(), filter)
+	if err != nil {
+		return nil, errors.Join(errors.New("repository: could not query slug matches:"), err)
+	}
+	defer cursor.Close(context.Background())
+
+	var results []models.SlugMatch
+	if err = cursor.All(context.Background(), &results); err != nil {
+		return nil, errors.Join(errors.New("repository: could not map slug matches:"), err)
+	}
+
+	return results, nil
+}
+
+// Returns nil without error if there is no such match
+func GetSlugMatchById(id bson.ObjectID) (*models.SlugMatch, error) {
+	filter := bson.D{{Key: "_id", Value: id}}
+
+	var result models.SlugMatch
+	if err := getSlugMatchesCollection().FindOne(context.Background(), filter).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		return nil, errors.Join(errors.New("repository: could not query slug match:"), err)
+	}
+
+	return &result, nil
+}
+
+// Returns stored match, so callers get its id on first insert
+func UpsertSlugMatch(match models.SlugMatch) (*models.SlugMatch, error) {
+	filter := bson.D{{Key: "backloggd_slug", Value: match.BackloggdSlug}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "title", Value: match.Title},
+		{Key: "status", Value: match.Status},
+		{Key: "igdb_id", Value: match.IgdbId},
+		{Key: "candidates", Value: match.Candidates},
+		{Key: "checked_at", Value: match.CheckedAt},
+	}}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var result models.SlugMatch
+	if err := getSlugMatchesCollection().FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&result); err != nil {
+		return nil, errors.Join(errors.New("repository: could not insert or update slug match:"), err)
+	}
+
+	return &result, nil
+}
+
+func UpdateSlugMatchStatus(id bson.ObjectID, status string, igdbId uint64) error {
+	filter := bson.D{{Key: "_id", Value: id}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: status}, {Key: "igdb_id", Value: igdbId}}}}
+
+	if _, err := getSlugMatchesCollection().UpdateOne(context.Background(), filter, update); err != nil {
+		return errors.Join(errors.New("repository: could not update slug match status:"), err)
+	}
+
+	return nil
+}
+
+func AddSlugMatchNotifiedUser(id bson.ObjectID, userId int64) error {
+	filter := bson.D{{Key: "_id", Value: id}}
+	update := bson.D{{Key: "$addToSet", Value: bson.D{{Key: "notified_user_ids", Value: userId}}}}
+
+	if _, err := getSlugMatchesCollection().UpdateOne(context.Background(), filter, update); err != nil {
+		return errors.Join(errors.New("repository: could not add slug match notified user:"), err)
+	}
+
+	return nil
+}
+
+func getSlugMatchesCollection() *mongo.Collection {
+	return configs.GetMongoDatabase().Collection("slug_matches")
+}
@@ -10,8 +10,8 @@ import (
 	"go.mongodb.org/mongo-driver/v2/mongo"
 )
 
-func GetSteamAppsDetails(appIds []uint64) ([]steam.AppDetails, error) {
-	filter := bson.M{"steam_appid": bson.M{"$in": appIds}}
+func GetSteamAppsDetails(appIds []uint64, countryCode string) ([]steam.AppDetails, error) {
+	filter := bson.M{"steam_appid": bson.M{"$in": appIds}, "country_code": countryCode}
 
 	cursor, err := getSteamAppDetailsCollection().Find(context.Background(), filter)
 	if err != nil {
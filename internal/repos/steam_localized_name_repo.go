@@ -0,0 +1,51 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Names checked before the given time are treated as expired and left out
+func GetSteamLocalizedNames(steamAppsIds []uint64, language string, checkedAfter time.Time) ([]steam.LocalizedName, error) {
+	filter := bson.M{"steam_appid": bson.M{"$in": steamAppsIds}, "language": language, "checked_at": bson.M{"$gte": checkedAfter}}
+
+	cursor, err := getSteamLocalizedNamesCollection().Find(context.Background(), filter)
+	if err != nil {
+		return nil, errors.Join(errors.New("repository: could not query steam localized names:"), err)
+	}
+	defer cursor.Close(context.Background())
+
+	var results []steam.LocalizedName
+	if err = cursor.All(context.Background(), &results); err != nil {
+		return nil, errors.Join(errors.New("repository: could not map steam localized names:"), err)
+	}
+
+	return results, nil
+}
+
+func UpsertSteamLocalizedName(localizedName steam.LocalizedName) error {
+	filter := bson.D{{Key: "steam_appid", Value: localizedName.SteamAppId}, {Key: "language", Value: localizedName.Language}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "name", Value: localizedName.Name},
+		{Key: "description", Value: localizedName.Description},
+		{Key: "checked_at", Value: localizedName.CheckedAt},
+	}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getSteamLocalizedNamesCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update steam localized name:"), err)
+	}
+
+	return nil
+}
+
+func getSteamLocalizedNamesCollection() *mongo.Collection {
+	return configs.GetMongoDatabase().Collection("steam_localized_names")
+}
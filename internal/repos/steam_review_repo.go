@@ -0,0 +1,52 @@
+package repos
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Summaries checked before the given time are treated as expired and left out
+func GetSteamReviewSummaries(steamAppsIds []uint64, checkedAfter time.Time) ([]steam.ReviewSummary, error) {
+	filter := bson.M{"steam_appid": bson.M{"$in": steamAppsIds}, "checked_at": bson.M{"$gte": checkedAfter}}
+
+	cursor, err := getSteamReviewSummariesCollection().Find(context.Background(), filter)
+	if err != nil {
+		return nil, errors.Join(errors.New("repository: could not query steam review summaries:"), err)
+	}
+	defer cursor.Close(context.Background())
+
+	var results []steam.ReviewSummary
+	if err = cursor.All(context.Background(), &results); err != nil {
+		return nil, errors.Join(errors.New("repository: could not map steam review summaries:"), err)
+	}
+
+	return results, nil
+}
+
+func UpsertSteamReviewSummary(reviewSummary steam.ReviewSummary) error {
+	filter := bson.D{{Key: "steam_appid", Value: reviewSummary.SteamAppId}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "review_score_desc", Value: reviewSummary.ScoreDescription},
+		{Key: "total_positive", Value: reviewSummary.TotalPositive},
+		{Key: "total_reviews", Value: reviewSummary.TotalReviews},
+		{Key: "checked_at", Value: reviewSummary.CheckedAt},
+	}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getSteamReviewSummariesCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update steam review summary:"), err)
+	}
+
+	return nil
+}
+
+func getSteamReviewSummariesCollection() *mongo.Collection {
+	return configs.GetMongoDatabase().Collection("steam_review_summaries")
+}
@@ -0,0 +1,60 @@
+package repos
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Returns both user and global overrides for the slugs
+func GetStoreOverrides(userId int64, slugs []string) ([]models.StoreOverride, error) {
+	filter := bson.M{
+		"user_id": bson.M{"$in": []int64{userId, models.GlobalOverrideUserId}},
+		"slug":    bson.M{"$in": slugs},
+	}
+
+	cursor, err := getStoreOverridesCollection().Find(context.Background(), filter)
+	if err != nil {
+		return nil, errors.Join(errors.New("repository: could not query store overrides:"), err)
+	}
+	defer cursor.Close(context.Background())
+
+	var results []models.StoreOverride
+	if err = cursor.All(context.Background(), &results); err != nil {
+		return nil, errors.Join(errors.New("repository: could not map store overrides:"), err)
+	}
+
+	return results, nil
+}
+
+func UpsertStoreOverride(override models.StoreOverride) error {
+	filter := bson.D{{Key: "user_id", Value: override.UserId}, {Key: "slug", Value: override.Slug}, {Key: "store", Value: override.Store}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "uids", Value: override.Uids}, {Key: "excluded", Value: override.Excluded}}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getStoreOverridesCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update store override:"), err)
+	}
+
+	return nil
+}
+
+func DeleteStoreOverride(userId int64, slug string, store igdb.Store) error {
+	filter := bson.D{{Key: "user_id", Value: userId}, {Key: "slug", Value: slug}, {Key: "store", Value: store}}
+
+	if _, err := getStoreOverridesCollection().DeleteOne(context.Background(), filter); err != nil {
+		return errors.Join(errors.New("repository: could not delete store override:"), err)
+	}
+
+	return nil
+}
+
+func getStoreOverridesCollection() *mongo.Collection {
+	return configs.GetMongoDatabase().Collection("store_overrides")
+}
@@ -0,0 +1,45 @@
+package repos
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func GetTelegramFiles(urls []string) ([]models.TelegramFile, error) {
+	filter := bson.M{"url": bson.M{"$in": urls}}
+
+	cursor, err := getTelegramFilesCollection().Find(context.Background(), filter)
+	if err != nil {
+		return nil, errors.Join(errors.New("repository: could not query telegram files:"), err)
+	}
+	defer cursor.Close(context.Background())
+
+	var results []models.TelegramFile
+	if err = cursor.All(context.Background(), &results); err != nil {
+		return nil, errors.Join(errors.New("repository: could not map telegram files:"), err)
+	}
+
+	return results, nil
+}
+
+func UpsertTelegramFile(file models.TelegramFile) error {
+	filter := bson.D{{Key: "url", Value: file.Url}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "file_id", Value: file.FileId}}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getTelegramFilesCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update telegram file:"), err)
+	}
+
+	return nil
+}
+
+func getTelegramFilesCollection() *mongo.Collection {
+	return configs.GetMongoDatabase().Collection("telegram_files")
+}
@@ -28,7 +28,7 @@ func GetUserSettings() ([]models.UserSettings, error) {
 
 func UpsertBackloggdProfileSetting(userId int64, backloggdProfileUrl string) error {
 	filter := bson.D{{Key: "user_id", Value: userId}}
-	update := bson.D{{Key: "$set", Value: bson.D{{Key: "backloggd_profile", Value: backloggdProfileUrl}}}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "backloggd_profile", Value: backloggdProfileUrl}, {Key: "wishlist_source", Value: models.WishlistSourceBackloggd}}}}
 	opts := options.UpdateOne().SetUpsert(true)
 
 	if _, err := getUserSettingsCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
@@ -38,6 +38,18 @@ func UpsertBackloggdProfileSetting(userId int64, backloggdProfileUrl string) err
 	return nil
 }
 
+func UpsertSteamProfileSetting(userId int64, steamId64 string) error {
+	filter := bson.D{{Key: "user_id", Value: userId}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "steam_profile", Value: steamId64}, {Key: "wishlist_source", Value: models.WishlistSourceSteam}}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getUserSettingsCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update user steam profile:"), err)
+	}
+
+	return nil
+}
+
 func UpsertCountrySetting(userId int64, countryCode string, currencyCode string) error {
 	filter := bson.D{{Key: "user_id", Value: userId}}
 	update := bson.D{{Key: "$set", Value: bson.D{{Key: "country_code", Value: countryCode}, {Key: "currency_code", Value: currencyCode}}}}
@@ -50,6 +62,84 @@ func UpsertCountrySetting(userId int64, countryCode string, currencyCode string)
 	return nil
 }
 
+func UpsertWishlistChangesSetting(userId int64, notifyWishlistChanges bool) error {
+	filter := bson.D{{Key: "user_id", Value: userId}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "notify_wishlist_changes", Value: notifyWishlistChanges}}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getUserSettingsCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update user wishlist changes setting:"), err)
+	}
+
+	return nil
+}
+
+func UpsertCompareRegionsSetting(userId int64, compareRegions bool, compareCountries []string) error {
+	filter := bson.D{{Key: "user_id", Value: userId}}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "compare_regions", Value: compareRegions},
+		{Key: "compare_countries", Value: compareCountries},
+	}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getUserSettingsCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update user compare regions setting:"), err)
+	}
+
+	return nil
+}
+
+func UpsertDisplayCurrencySetting(userId int64, displayCurrency string) error {
+	filter := bson.D{{Key: "user_id", Value: userId}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "display_currency", Value: displayCurrency}}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getUserSettingsCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update user display currency setting:"), err)
+	}
+
+	return nil
+}
+
+func UpsertLanguageSetting(userId int64, language string) error {
+	filter := bson.D{{Key: "user_id", Value: userId}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "language", Value: language}}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getUserSettingsCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update user language setting:"), err)
+	}
+
+	return nil
+}
+
+func UpsertFiltersSetting(userId int64, filters models.GameFilters) error {
+	filter := bson.D{{Key: "user_id", Value: userId}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "filters", Value: filters}}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getUserSettingsCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update user filters:"), err)
+	}
+
+	return nil
+}
+
+func GetUserSettingsByUserId(userId int64) (*models.UserSettings, error) {
+	filter := bson.D{{Key: "user_id", Value: userId}}
+
+	var result models.UserSettings
+	if err := getUserSettingsCollection().FindOne(context.Background(), filter).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		return nil, errors.Join(errors.New("repository: could not query user setting:"), err)
+	}
+
+	return &result, nil
+}
+
 func DeleteUserSettings(userId int64) error {
 	filter := bson.D{{Key: "user_id", Value: userId}}
 
@@ -0,0 +1,69 @@
+package repos
+
+import (
+	"context"
+	"errors"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+func GetWatchlist(userId int64) ([]models.WatchlistEntry, error) {
+	filter := bson.D{{Key: "user_id", Value: userId}}
+
+	cursor, err := getWatchlistCollection().Find(context.Background(), filter)
+	if err != nil {
+		return nil, errors.Join(errors.New("repository: could not query watchlist:"), err)
+	}
+	defer cursor.Close(context.Background())
+
+	var results []models.WatchlistEntry
+	if err = cursor.All(context.Background(), &results); err != nil {
+		return nil, errors.Join(errors.New("repository: could not map watchlist:"), err)
+	}
+
+	return results, nil
+}
+
+func UpsertWatchlistEntry(entry models.WatchlistEntry) error {
+	filter := getWatchlistEntryFilter(entry.UserId, entry.IgdbId, entry.SteamAppId)
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "steam_app_id", Value: entry.SteamAppId}, {Key: "slug", Value: entry.Slug}, {Key: "name", Value: entry.Name}}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getWatchlistCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update watchlist entry:"), err)
+	}
+
+	return nil
+}
+
+func DeleteWatchlistEntry(userId int64, igdbId uint64) error {
+	if _, err := getWatchlistCollection().DeleteOne(context.Background(), getWatchlistEntryFilter(userId, igdbId, 0)); err != nil {
+		return errors.Join(errors.New("repository: could not delete watchlist entry:"), err)
+	}
+
+	return nil
+}
+
+func DeleteSteamWatchlistEntry(userId int64, steamAppId uint64) error {
+	if _, err := getWatchlistCollection().DeleteOne(context.Background(), getWatchlistEntryFilter(userId, 0, steamAppId)); err != nil {
+		return errors.Join(errors.New("repository: could not delete steam watchlist entry:"), err)
+	}
+
+	return nil
+}
+
+func getWatchlistEntryFilter(userId int64, igdbId uint64, steamAppId uint64) bson.D {
+	if igdbId == 0 {
+		return bson.D{{Key: "user_id", Value: userId}, {Key: "igdb_id", Value: uint64(0)}, {Key: "steam_app_id", Value: steamAppId}}
+	}
+
+	return bson.D{{Key: "user_id", Value: userId}, {Key: "igdb_id", Value: igdbId}}
+}
+
+func getWatchlistCollection() *mongo.Collection {
+	return configs.GetMongoDatabase().Collection("watchlists")
+}
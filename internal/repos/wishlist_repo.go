@@ -8,6 +8,7 @@ import (
 	"github.com/theverysameliquidsnake/sales-bot/internal/models"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 func GetWishlists() ([]models.Wishlist, error) {
@@ -25,6 +26,22 @@ func GetWishlists() ([]models.Wishlist, error) {
 	return results, nil
 }
 
+// Returns nil without error if the user has no stored wishlist yet
+func GetWishlist(userId int64) (*models.Wishlist, error) {
+	filter := bson.D{{Key: "user_id", Value: userId}}
+
+	var result models.Wishlist
+	if err := getWishlistCollection().FindOne(context.Background(), filter).Decode(&result); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+
+		return nil, errors.Join(errors.New("repository: could not query wishlist:"), err)
+	}
+
+	return &result, nil
+}
+
 func InsertWishlists(wishlists []models.Wishlist) error {
 	if _, err := getWishlistCollection().InsertMany(context.Background(), wishlists); err != nil {
 		return errors.Join(errors.New("repository: could not insert wishlists:"), err)
@@ -33,6 +50,18 @@ func InsertWishlists(wishlists []models.Wishlist) error {
 	return nil
 }
 
+func UpsertWishlist(wishlist models.Wishlist) error {
+	filter := bson.D{{Key: "user_id", Value: wishlist.UserId}}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "slug_list", Value: wishlist.SlugList}, {Key: "steam_app_ids", Value: wishlist.SteamAppIds}}}}
+	opts := options.UpdateOne().SetUpsert(true)
+
+	if _, err := getWishlistCollection().UpdateOne(context.Background(), filter, update, opts); err != nil {
+		return errors.Join(errors.New("repository: could not insert or update wishlist:"), err)
+	}
+
+	return nil
+}
+
 func DropWishlists() error {
 	if err := getWishlistCollection().Drop(context.Background()); err != nil {
 		return errors.Join(errors.New("repository: could not delete wishlists collection:"), err)
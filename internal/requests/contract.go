@@ -0,0 +1,34 @@
+package requests
+
+import (
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Names upstreams are reported under by the monitor
+const (
+	SteamAppDetailsUpstream = "steam appdetails"
+	IgdbGamesUpstream       = "igdb games"
+)
+
+// Fields the pipeline relies on, their absence means the upstream changed its schema
+var (
+	steamAppDetailsContract = []string{"type", "name", "steam_appid", "is_free", "release_date"}
+	// Basic filter leaves prices and release dates out
+	steamLocalizedAppDetailsContract = []string{"name", "steam_appid", "short_description"}
+	steamPriceOverviewContract       = []string{"currency", "initial", "final", "discount_percent"}
+	igdbGameContract                 = []string{"id", "name", "slug"}
+)
+
+// Returns comma separated names of fields missing from the json object at path, empty when all are there
+func findMissingFields(body []byte, fields []string, path ...any) string {
+	var missingFields []string
+	for _, field := range fields {
+		if jsoniter.Get(body, append(path, field)...).ValueType() == jsoniter.InvalidValue {
+			missingFields = append(missingFields, field)
+		}
+	}
+
+	return strings.Join(missingFields, ", ")
+}
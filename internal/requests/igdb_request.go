@@ -1,47 +1,224 @@
 package requests
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/theverysameliquidsnake/sales-bot/internal/configs"
 	"github.com/theverysameliquidsnake/sales-bot/internal/models/igdb"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
 )
 
-func RequestGamesFromIgdb(slugs []string) ([]igdb.Game, error) {
-	client := &http.Client{}
-	payload := "fields *, external_games.*, external_games.external_game_source.*, cover.*; where slug = (\"" + strings.Join(slugs, "\", \"") + "\");"
+const (
+	igdbGameFields = "fields *, external_games.*, external_games.external_game_source.*, cover.*, platforms.id, platforms.name, platforms.abbreviation, genres.id, genres.name, themes.id, themes.name, game_modes.id, game_modes.name, release_dates.id, release_dates.date, release_dates.human, release_dates.platform, alternative_names.id, alternative_names.name;"
 
-	request, err := http.NewRequest("POST", "https://api.igdb.com/v4/games", strings.NewReader(payload))
-	if err != nil {
-		return nil, errors.Join(errors.New("request: could not create request to igdb:"), err)
+	// Max results igdb returns per query
+	igdbQueryLimit = 500
+	// Igdb allows 4 requests per second
+	igdbRequestsPerSecond = 4
+)
+
+type IgdbStatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *IgdbStatusError) Error() string {
+	return fmt.Sprintf("request: igdb responded with status: %d %s", e.StatusCode, e.Body)
+}
+
+type IgdbApi interface {
+	RequestGamesBySlugs(ctx context.Context, slugs []string) ([]igdb.Game, error)
+	RequestGamesByIds(ctx context.Context, ids []uint64) ([]igdb.Game, error)
+	RequestGamesBySteamAppIds(ctx context.Context, steamAppIds []uint64) ([]igdb.Game, error)
+	SearchGames(ctx context.Context, name string, limit int) ([]igdb.Game, error)
+	RequestGamesByAlternativeName(ctx context.Context, name string, limit int) ([]igdb.Game, error)
+}
+
+type IgdbClient struct {
+	baseUrl    string
+	tokens     configs.IgdbTokenSource
+	httpClient *http.Client
+	limiter    *types.RateLimiter
+	monitor    *types.UpstreamMonitor
+}
+
+func NewIgdbClient(baseUrl string, tokens configs.IgdbTokenSource, limiters *types.HostRateLimiters, monitor *types.UpstreamMonitor) *IgdbClient {
+	return &IgdbClient{
+		baseUrl:    baseUrl,
+		tokens:     tokens,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		limiter:    limiters.Get(baseUrl, igdbRequestsPerSecond),
+		monitor:    monitor,
 	}
+}
 
-	request.Header.Set("Content-Type", "text/plain")
-	for key, value := range configs.ConstructAdditionalHeadersForIgdb() {
-		request.Header.Set(key, value)
+func (c *IgdbClient) RequestGamesBySlugs(ctx context.Context, slugs []string) ([]igdb.Game, error) {
+	var quotedSlugs []string
+	for _, slug := range slugs {
+		quotedSlugs = append(quotedSlugs, quoteIgdbString(slug))
+	}
+
+	return c.requestGamesInChunks(ctx, quotedSlugs, func(values string) string {
+		return fmt.Sprintf("where slug = (%s);", values)
+	})
+}
+
+func (c *IgdbClient) RequestGamesByIds(ctx context.Context, ids []uint64) ([]igdb.Game, error) {
+	var formattedIds []string
+	for _, id := range ids {
+		formattedIds = append(formattedIds, strconv.FormatUint(id, 10))
+	}
+
+	return c.requestGamesInChunks(ctx, formattedIds, func(values string) string {
+		return fmt.Sprintf("where id = (%s);", values)
+	})
+}
+
+func (c *IgdbClient) RequestGamesBySteamAppIds(ctx context.Context, steamAppIds []uint64) ([]igdb.Game, error) {
+	var quotedIds []string
+	for _, steamAppId := range steamAppIds {
+		quotedIds = append(quotedIds, quoteIgdbString(strconv.FormatUint(steamAppId, 10)))
 	}
 
-	response, err := client.Do(request)
+	return c.requestGamesInChunks(ctx, quotedIds, func(values string) string {
+		return fmt.Sprintf("where external_games.external_game_source = %d & external_games.uid = (%s);", igdb.ExternalGameSourceSteam, values)
+	})
+}
+
+func (c *IgdbClient) SearchGames(ctx context.Context, name string, limit int) ([]igdb.Game, error) {
+	payload := fmt.Sprintf("search %s; %s limit %d;", quoteIgdbString(name), igdbGameFields, limit)
+
+	return c.requestGames(ctx, payload)
+}
+
+// Case insensitive exact match on any alternative name
+func (c *IgdbClient) RequestGamesByAlternativeName(ctx context.Context, name string, limit int) ([]igdb.Game, error) {
+	payload := fmt.Sprintf("%s where alternative_names.name ~ %s; limit %d;", igdbGameFields, quoteIgdbString(name), limit)
+
+	return c.requestGames(ctx, payload)
+}
+
+// Splits values so that each query fits into igdb result limit
+func (c *IgdbClient) requestGamesInChunks(ctx context.Context, values []string, where func(values string) string) ([]igdb.Game, error) {
+	var games []igdb.Game
+	for start := 0; start < len(values); start += igdbQueryLimit {
+		end := min(start+igdbQueryLimit, len(values))
+
+		payload := fmt.Sprintf("%s %s limit %d;", igdbGameFields, where(strings.Join(values[start:end], ", ")), igdbQueryLimit)
+		chunkGames, err := c.requestGames(ctx, payload)
+		if err != nil {
+			return nil, errors.Join(fmt.Errorf("request: could not request igdb games chunk: %d-%d", start, end), err)
+		}
+
+		games = append(games, chunkGames...)
+	}
+
+	return games, nil
+}
+
+func (c *IgdbClient) requestGames(ctx context.Context, payload string) ([]igdb.Game, error) {
+	token, err := c.tokens.GetToken()
 	if err != nil {
-		return nil, errors.Join(errors.New("request: could not do request to igdb:"), err)
+		return nil, errors.Join(errors.New("request: could not get igdb token:"), err)
 	}
-	defer response.Body.Close()
 
-	body, err := io.ReadAll(response.Body)
+	body, statusCode, err := c.doRequest(ctx, payload, token)
 	if err != nil {
-		return nil, errors.Join(errors.New("request: could not read response from igdb:"), err)
+		return nil, err
+	}
+
+	// Token could be revoked before it expired, so refresh it and retry once
+	if statusCode == http.StatusUnauthorized {
+		c.tokens.InvalidateToken(token)
+
+		token, err = c.tokens.GetToken()
+		if err != nil {
+			return nil, errors.Join(errors.New("request: could not refresh igdb token:"), err)
+		}
+
+		body, statusCode, err = c.doRequest(ctx, payload, token)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.monitor.RecordResult(IgdbGamesUpstream, statusCode == http.StatusOK)
+	if statusCode != http.StatusOK {
+		return nil, &IgdbStatusError{StatusCode: statusCode, Body: string(body)}
 	}
 
+	c.checkGamesContract(body)
+
 	var games []igdb.Game
 	err = json.Unmarshal([]byte(jsoniter.Get(body).ToString()), &games)
 	if err != nil {
 		return nil, errors.Join(errors.New("request: could not map response from igdb to variable:"), err)
 	}
 
+	for i := range games {
+		games[i].StoreIds = igdb.ExtractStoreIds(games[i].ExternalGames)
+	}
+
 	return games, nil
 }
+
+func (c *IgdbClient) doRequest(ctx context.Context, payload string, token string) ([]byte, int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseUrl+"/games", strings.NewReader(payload))
+	if err != nil {
+		return nil, 0, errors.Join(errors.New("request: could not create request to igdb:"), err)
+	}
+
+	request.Header.Set("Content-Type", "text/plain")
+	for key, value := range configs.ConstructAdditionalHeadersForIgdb(c.tokens.ClientId(), token) {
+		request.Header.Set(key, value)
+	}
+
+	if err := c.limiter.Wait(ctx); err != nil {
+		return nil, 0, errors.Join(errors.New("request: gave up waiting for igdb:"), err)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, 0, errors.Join(errors.New("request: could not do request to igdb:"), err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, errors.Join(errors.New("request: could not read response from igdb:"), err)
+	}
+
+	return body, response.StatusCode, nil
+}
+
+// Stops at the first broken game, one sample is enough to tell the schema changed
+func (c *IgdbClient) checkGamesContract(body []byte) {
+	if jsoniter.Get(body).ValueType() != jsoniter.ArrayValue {
+		c.monitor.ReportDrift(IgdbGamesUpstream, "response is not an array", string(body))
+		return
+	}
+
+	for i := 0; i < jsoniter.Get(body).Size(); i++ {
+		if missingFields := findMissingFields(body, igdbGameContract, i); missingFields != "" {
+			c.monitor.ReportDrift(IgdbGamesUpstream, "missing fields: "+missingFields, jsoniter.Get(body, i).ToString())
+			return
+		}
+	}
+}
+
+// Wraps value into apicalypse string literal
+func quoteIgdbString(value string) string {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "\"", "\\\"")
+
+	return "\"" + value + "\""
+}
@@ -0,0 +1,25 @@
+package requests
+
+import "testing"
+
+func TestQuoteIgdbString(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: "hollow-knight", want: `"hollow-knight"`},
+		{name: "empty", value: "", want: `""`},
+		{name: "quotes", value: `The "Game"`, want: `"The \"Game\""`},
+		{name: "backslash goes first", value: `a\b`, want: `"a\\b"`},
+		{name: "escaped quote can not close the string", value: `\"; fields *;`, want: `"\\\"; fields *;"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := quoteIgdbString(test.value); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
@@ -1,48 +1,99 @@
 package requests
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
+	"net/url"
 	"strconv"
-	"time"
 
 	jsoniter "github.com/json-iterator/go"
 	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
 )
 
-func RequestAppDetailsFromSteam(appDetailsIds []uint64, countryCode string) ([]steam.AppDetails, error) {
+// Names come in English, prices are shared by users of every language
+func (c *SteamClient) RequestAppDetails(ctx context.Context, appDetailsIds []uint64, countryCode string) ([]steam.AppDetails, map[uint64]error) {
+	return c.requestAppsDetails(ctx, appDetailsIds, "l=english&cc="+url.QueryEscape(countryCode), steamAppDetailsContract)
+}
+
+// Only basic details without prices, enough for names and descriptions in the given Steam language
+func (c *SteamClient) RequestLocalizedAppDetails(ctx context.Context, appDetailsIds []uint64, language string) ([]steam.AppDetails, map[uint64]error) {
+	return c.requestAppsDetails(ctx, appDetailsIds, "filters=basic&l="+url.QueryEscape(language), steamLocalizedAppDetailsContract)
+}
+
+func (c *SteamClient) requestAppsDetails(ctx context.Context, appDetailsIds []uint64, query string, contract []string) ([]steam.AppDetails, map[uint64]error) {
 	var appsDetails []steam.AppDetails
-	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	appsErrors := make(map[uint64]error)
 
 	for _, appDetailId := range appDetailsIds {
-		response, err := http.Get(fmt.Sprintf("https://store.steampowered.com/api/appdetails/?appids=%d&l=english&cc=%s", appDetailId, countryCode))
-		if err != nil {
-			return nil, errors.Join(errors.New("request: could not create request to steam:"), err)
+		// Rest of the batch is reported as cut without asking Steam
+		if err := ctx.Err(); err != nil {
+			appsErrors[appDetailId] = err
+			continue
 		}
-		defer response.Body.Close()
 
-		body, err := io.ReadAll(response.Body)
+		appDetails, err := c.requestAppDetails(ctx, appDetailId, query, contract)
 		if err != nil {
-			return nil, errors.Join(errors.New("request: could not read response from steam:"), err)
+			appsErrors[appDetailId] = err
+			continue
 		}
 
-		if !jsoniter.Get(body, strconv.FormatUint(uint64(appDetailId), 10), "success").ToBool() {
-			return nil, errors.New("reguest: could not confirm success from steam api")
-		}
+		appsDetails = append(appsDetails, appDetails)
+	}
 
-		var appDetails steam.AppDetails
-		err = json.Unmarshal([]byte(jsoniter.Get(body, strconv.FormatUint(uint64(appDetailId), 10), "data").ToString()), &appDetails)
-		if err != nil {
-			return nil, errors.Join(errors.New("request: could not map response from steam to variable:"), err)
+	return appsDetails, appsErrors
+}
+
+// Success false is a normal answer for delisted apps, only transport errors and answers off the contract count as failures
+func (c *SteamClient) requestAppDetails(ctx context.Context, appDetailId uint64, query string, contract []string) (steam.AppDetails, error) {
+	var appDetails steam.AppDetails
+
+	body, err := c.getBody(ctx, fmt.Sprintf("%s/api/appdetails/?appids=%d&%s", c.storeBaseUrl, appDetailId, query), c.storeLimiter)
+	if err != nil {
+		// Requests cut by the caller say nothing about Steam
+		if ctx.Err() == nil {
+			c.monitor.RecordResult(SteamAppDetailsUpstream, false)
 		}
+		return appDetails, err
+	}
 
-		appsDetails = append(appsDetails, appDetails)
+	key := strconv.FormatUint(appDetailId, 10)
+	success := jsoniter.Get(body, key, "success")
+	if success.ValueType() != jsoniter.BoolValue {
+		c.monitor.RecordResult(SteamAppDetailsUpstream, false)
+		c.monitor.ReportDrift(SteamAppDetailsUpstream, "missing fields: success", string(body))
+		return appDetails, fmt.Errorf("request: no success flag in steam response: %d", appDetailId)
+	}
+
+	if !success.ToBool() {
+		c.monitor.RecordResult(SteamAppDetailsUpstream, true)
+		return appDetails, ErrSteamAppUnavailable
+	}
+
+	c.checkAppDetailsContract(body, key, contract)
+
+	err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal([]byte(jsoniter.Get(body, key, "data").ToString()), &appDetails)
+	if err != nil {
+		c.monitor.RecordResult(SteamAppDetailsUpstream, false)
+		c.monitor.ReportDrift(SteamAppDetailsUpstream, "data does not decode", string(body))
+		return appDetails, errors.Join(errors.New("request: could not map response from steam to variable:"), err)
+	}
+
+	c.monitor.RecordResult(SteamAppDetailsUpstream, true)
+	return appDetails, nil
+}
 
-		// Wait to match rate limits
-		time.Sleep(2 * time.Second)
+func (c *SteamClient) checkAppDetailsContract(body []byte, key string, contract []string) {
+	if missingFields := findMissingFields(body, contract, key, "data"); missingFields != "" {
+		c.monitor.ReportDrift(SteamAppDetailsUpstream, "missing fields: "+missingFields, string(body))
 	}
 
-	return appsDetails, nil
+	// Price is optional, but when present it has to be complete
+	if jsoniter.Get(body, key, "data", "price_overview").ValueType() == jsoniter.InvalidValue {
+		return
+	}
+
+	if missingFields := findMissingFields(body, steamPriceOverviewContract, key, "data", "price_overview"); missingFields != "" {
+		c.monitor.ReportDrift(SteamAppDetailsUpstream, "missing price fields: "+missingFields, string(body))
+	}
 }
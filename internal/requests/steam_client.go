@@ -0,0 +1,143 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+	"github.com/theverysameliquidsnake/sales-bot/internal/types"
+)
+
+const (
+	// Steam store tolerates roughly one appdetails request per two seconds
+	steamStoreRequestsPerSecond = 0.5
+	// Web api allows a hundred thousand calls a day, one per second stays far below that
+	steamApiRequestsPerSecond = 1
+	// Deadline of a single attempt, including reading the body
+	steamRequestTimeout = 15 * time.Second
+	steamMaxAttempts    = 4
+	// Doubled after every failed attempt
+	steamRetryBackoff = 2 * time.Second
+)
+
+// Delisted apps and apps not sold in the requested country come back with success false
+var ErrSteamAppUnavailable = errors.New("request: steam app is unavailable")
+
+type SteamStatusError struct {
+	StatusCode int
+}
+
+func (e *SteamStatusError) Error() string {
+	return fmt.Sprintf("request: steam responded with status: %d", e.StatusCode)
+}
+
+type SteamApi interface {
+	// Apps that could not be fetched are left out of the result and reported in the error map
+	RequestAppDetails(ctx context.Context, appDetailsIds []uint64, countryCode string) ([]steam.AppDetails, map[uint64]error)
+	RequestLocalizedAppDetails(ctx context.Context, appDetailsIds []uint64, language string) ([]steam.AppDetails, map[uint64]error)
+	// Packages that could not be fetched are left out of the result and reported in the error map
+	RequestPackageDetails(ctx context.Context, packageIds []uint64, countryCode string) ([]steam.PackageDetails, map[uint64]error)
+	RequestBundleDetails(ctx context.Context, bundleIds []uint64, countryCode string) ([]steam.BundleDetails, error)
+	RequestReviewSummary(ctx context.Context, steamAppId uint64) (steam.ReviewSummary, error)
+	RequestWishlist(ctx context.Context, steamId64 string) ([]uint64, error)
+}
+
+type SteamClient struct {
+	storeBaseUrl string
+	apiBaseUrl   string
+	httpClient   *http.Client
+	storeLimiter *types.RateLimiter
+	apiLimiter   *types.RateLimiter
+	monitor      *types.UpstreamMonitor
+}
+
+func NewSteamClient(storeBaseUrl string, apiBaseUrl string, limiters *types.HostRateLimiters, monitor *types.UpstreamMonitor) *SteamClient {
+	return &SteamClient{
+		storeBaseUrl: storeBaseUrl,
+		apiBaseUrl:   apiBaseUrl,
+		httpClient:   &http.Client{},
+		storeLimiter: limiters.Get(storeBaseUrl, steamStoreRequestsPerSecond),
+		apiLimiter:   limiters.Get(apiBaseUrl, steamApiRequestsPerSecond),
+		monitor:      monitor,
+	}
+}
+
+// Retries throttled, failed and timed out requests with backoff, giving up once the context is done
+func (c *SteamClient) getBody(ctx context.Context, url string, limiter *types.RateLimiter) ([]byte, error) {
+	backoff := steamRetryBackoff
+
+	var lastErr error
+	for attempt := 1; attempt <= steamMaxAttempts; attempt++ {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, errors.Join(errors.New("request: gave up waiting for steam:"), err)
+		}
+
+		body, retryAfter, err := c.doGet(ctx, url)
+		if err == nil {
+			return body, nil
+		}
+
+		lastErr = err
+
+		var statusErr *SteamStatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode != http.StatusTooManyRequests && statusErr.StatusCode < http.StatusInternalServerError {
+			return nil, err
+		}
+
+		if attempt == steamMaxAttempts {
+			break
+		}
+
+		// Steam sometimes tells how long to back off on 429
+		wait := backoff
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, errors.Join(errors.New("request: gave up retrying steam:"), lastErr, ctx.Err())
+		}
+
+		backoff *= 2
+	}
+
+	return nil, errors.Join(fmt.Errorf("request: steam did not respond after %d attempts:", steamMaxAttempts), lastErr)
+}
+
+// Body is read and closed within the attempt, so connections are returned to the pool right away
+func (c *SteamClient) doGet(ctx context.Context, url string) ([]byte, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, steamRequestTimeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, errors.Join(errors.New("request: could not create request to steam:"), err)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, 0, errors.Join(errors.New("request: could not do request to steam:"), err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		retryAfter, _ := strconv.Atoi(response.Header.Get("Retry-After"))
+		return nil, time.Duration(retryAfter) * time.Second, &SteamStatusError{StatusCode: response.StatusCode}
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, 0, errors.Join(errors.New("request: could not read response from steam:"), err)
+	}
+
+	return body, 0, nil
+}
@@ -0,0 +1,88 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+)
+
+// Packages not sold in the region come back with success false, same as apps
+var ErrSteamPackageUnavailable = errors.New("request: steam package is unavailable")
+
+func (c *SteamClient) RequestPackageDetails(ctx context.Context, packageIds []uint64, countryCode string) ([]steam.PackageDetails, map[uint64]error) {
+	var packagesDetails []steam.PackageDetails
+	packagesErrors := make(map[uint64]error)
+
+	for _, packageId := range packageIds {
+		// Rest of the batch is reported as cut without asking Steam
+		if err := ctx.Err(); err != nil {
+			packagesErrors[packageId] = err
+			continue
+		}
+
+		packageDetails, err := c.requestPackageDetails(ctx, packageId, countryCode)
+		if err != nil {
+			packagesErrors[packageId] = err
+			continue
+		}
+
+		packagesDetails = append(packagesDetails, packageDetails)
+	}
+
+	return packagesDetails, packagesErrors
+}
+
+func (c *SteamClient) requestPackageDetails(ctx context.Context, packageId uint64, countryCode string) (steam.PackageDetails, error) {
+	var packageDetails steam.PackageDetails
+
+	body, err := c.getBody(ctx, fmt.Sprintf("%s/api/packagedetails/?packageids=%d&cc=%s", c.storeBaseUrl, packageId, countryCode), c.storeLimiter)
+	if err != nil {
+		return packageDetails, err
+	}
+
+	key := strconv.FormatUint(packageId, 10)
+	if !jsoniter.Get(body, key, "success").ToBool() {
+		return packageDetails, ErrSteamPackageUnavailable
+	}
+
+	err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal([]byte(jsoniter.Get(body, key, "data").ToString()), &packageDetails)
+	if err != nil {
+		return packageDetails, errors.Join(errors.New("request: could not map package response from steam to variable:"), err)
+	}
+
+	packageDetails.PackageId = packageId
+
+	return packageDetails, nil
+}
+
+func (c *SteamClient) RequestBundleDetails(ctx context.Context, bundleIds []uint64, countryCode string) ([]steam.BundleDetails, error) {
+	if len(bundleIds) == 0 {
+		return nil, nil
+	}
+
+	body, err := c.getBody(ctx, fmt.Sprintf("%s/actions/ajaxresolvebundles?bundleids=%s&cc=%s&l=english", c.storeBaseUrl, joinUints(bundleIds), countryCode), c.storeLimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundlesDetails []steam.BundleDetails
+	if err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal(body, &bundlesDetails); err != nil {
+		return nil, errors.Join(errors.New("request: could not map bundle response from steam to variable:"), err)
+	}
+
+	return bundlesDetails, nil
+}
+
+func joinUints(values []uint64) string {
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		formatted = append(formatted, strconv.FormatUint(value, 10))
+	}
+
+	return strings.Join(formatted, ",")
+}
@@ -0,0 +1,32 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	jsoniter "github.com/json-iterator/go"
+	"github.com/theverysameliquidsnake/sales-bot/internal/models/steam"
+)
+
+func (c *SteamClient) RequestReviewSummary(ctx context.Context, steamAppId uint64) (steam.ReviewSummary, error) {
+	var reviewSummary steam.ReviewSummary
+
+	// Only the summary is needed, so no reviews are requested
+	body, err := c.getBody(ctx, fmt.Sprintf("%s/appreviews/%d?json=1&language=all&purchase_type=all&num_per_page=0", c.storeBaseUrl, steamAppId), c.storeLimiter)
+	if err != nil {
+		return reviewSummary, err
+	}
+
+	if jsoniter.Get(body, "success").ToInt() != 1 {
+		return reviewSummary, ErrSteamAppUnavailable
+	}
+
+	err = jsoniter.ConfigCompatibleWithStandardLibrary.Unmarshal([]byte(jsoniter.Get(body, "query_summary").ToString()), &reviewSummary)
+	if err != nil {
+		return reviewSummary, errors.Join(errors.New("request: could not map review summary from steam to variable:"), err)
+	}
+
+	reviewSummary.SteamAppId = steamAppId
+	return reviewSummary, nil
+}
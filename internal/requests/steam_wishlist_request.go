@@ -0,0 +1,50 @@
+package requests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Private profiles hide their wishlist
+var ErrSteamWishlistUnavailable = errors.New("request: steam wishlist is unavailable")
+
+type steamWishlistItem struct {
+	AppId uint64 `json:"appid"`
+}
+
+func (c *SteamClient) RequestWishlist(ctx context.Context, steamId64 string) ([]uint64, error) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+
+	body, err := c.getBody(ctx, fmt.Sprintf("%s/IWishlistService/GetWishlist/v1/?steamid=%s", c.apiBaseUrl, url.QueryEscape(steamId64)), c.apiLimiter)
+	if err != nil {
+		var statusErr *SteamStatusError
+		if errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden) {
+			return nil, errors.Join(ErrSteamWishlistUnavailable, err)
+		}
+
+		return nil, errors.Join(errors.New("request: could not get steam wishlist:"), err)
+	}
+
+	// Emptied wishlists come back with an empty response object
+	itemsJson := jsoniter.Get(body, "response", "items")
+	if itemsJson.ValueType() != jsoniter.ArrayValue {
+		return nil, nil
+	}
+
+	var items []steamWishlistItem
+	if err = json.Unmarshal([]byte(itemsJson.ToString()), &items); err != nil {
+		return nil, errors.Join(errors.New("request: could not map response from steam wishlist to variable:"), err)
+	}
+
+	var appsIds []uint64
+	for _, item := range items {
+		appsIds = append(appsIds, item.AppId)
+	}
+
+	return appsIds, nil
+}
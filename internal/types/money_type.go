@@ -0,0 +1,159 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Steam price in a currency. These are not ISO 4217 minor units: every currency is kept
+// in hundredths, the way Steam reports it, yen and won included
+type Money struct {
+	Hundredths int64  `json:"amount" bson:"amount"`
+	Currency   string `json:"currency" bson:"currency"`
+}
+
+type currencyFormat struct {
+	symbol             string
+	isSuffix           bool
+	decimals           int
+	decimalSeparator   string
+	thousandsSeparator string
+}
+
+// Follows the way Steam store shows prices in each currency. Readers of a known language get their own separators,
+// see FormatLocale
+var currencyFormats = map[string]currencyFormat{
+	"USD": {symbol: "$", decimals: 2, decimalSeparator: ".", thousandsSeparator: ","},
+	"EUR": {symbol: "€", isSuffix: true, decimals: 2, decimalSeparator: ",", thousandsSeparator: " "},
+	"GBP": {symbol: "£", decimals: 2, decimalSeparator: ".", thousandsSeparator: ","},
+	"PLN": {symbol: "zł", isSuffix: true, decimals: 2, decimalSeparator: ",", thousandsSeparator: " "},
+	"RUB": {symbol: " ₽", isSuffix: true, decimals: 0, thousandsSeparator: " "},
+	"UAH": {symbol: "₴", isSuffix: true, decimals: 0, thousandsSeparator: " "},
+	"KZT": {symbol: "₸", isSuffix: true, decimals: 0, thousandsSeparator: " "},
+	"NOK": {symbol: " kr", isSuffix: true, decimals: 2, decimalSeparator: ",", thousandsSeparator: " "},
+	"CHF": {symbol: "CHF ", decimals: 2, decimalSeparator: ".", thousandsSeparator: "'"},
+	"BRL": {symbol: "R$ ", decimals: 2, decimalSeparator: ",", thousandsSeparator: "."},
+	"CAD": {symbol: "CDN$ ", decimals: 2, decimalSeparator: ".", thousandsSeparator: ","},
+	"AUD": {symbol: "A$ ", decimals: 2, decimalSeparator: ".", thousandsSeparator: ","},
+	"NZD": {symbol: "NZ$ ", decimals: 2, decimalSeparator: ".", thousandsSeparator: ","},
+	"MXN": {symbol: "Mex$ ", decimals: 2, decimalSeparator: ".", thousandsSeparator: ","},
+	"CNY": {symbol: "¥ ", decimals: 2, decimalSeparator: ".", thousandsSeparator: ","},
+	"JPY": {symbol: "¥ ", decimals: 0, thousandsSeparator: ","},
+	"KRW": {symbol: "₩ ", decimals: 0, thousandsSeparator: ","},
+	"INR": {symbol: "₹ ", decimals: 0, thousandsSeparator: ","},
+	"IDR": {symbol: "Rp ", decimals: 0, thousandsSeparator: " "},
+	"VND": {symbol: "₫", isSuffix: true, decimals: 0, thousandsSeparator: "."},
+	"TRY": {symbol: "₺", decimals: 2, decimalSeparator: ",", thousandsSeparator: "."},
+}
+
+type numberSeparators struct {
+	decimal   string
+	thousands string
+}
+
+// By base language code, the way Telegram reports it. Symbol, its side and decimals stay the currency's
+var languageSeparators = map[string]numberSeparators{
+	"bg": {decimal: ",", thousands: " "},
+	"cs": {decimal: ",", thousands: " "},
+	"da": {decimal: ",", thousands: "."},
+	"de": {decimal: ",", thousands: "."},
+	"el": {decimal: ",", thousands: "."},
+	"en": {decimal: ".", thousands: ","},
+	"es": {decimal: ",", thousands: "."},
+	"fi": {decimal: ",", thousands: " "},
+	"fr": {decimal: ",", thousands: " "},
+	"hu": {decimal: ",", thousands: " "},
+	"id": {decimal: ",", thousands: "."},
+	"it": {decimal: ",", thousands: "."},
+	"ja": {decimal: ".", thousands: ","},
+	"ko": {decimal: ".", thousands: ","},
+	"nb": {decimal: ",", thousands: " "},
+	"nl": {decimal: ",", thousands: "."},
+	"no": {decimal: ",", thousands: " "},
+	"pl": {decimal: ",", thousands: " "},
+	"pt": {decimal: ",", thousands: "."},
+	"ro": {decimal: ",", thousands: "."},
+	"ru": {decimal: ",", thousands: " "},
+	"sv": {decimal: ",", thousands: " "},
+	"th": {decimal: ".", thousands: ","},
+	"tr": {decimal: ",", thousands: "."},
+	"uk": {decimal: ",", thousands: " "},
+	"vi": {decimal: ",", thousands: "."},
+	"zh": {decimal: ".", thousands: ","},
+}
+
+func NewMoney(hundredths int64, currency string) Money {
+	return Money{Hundredths: hundredths, Currency: strings.ToUpper(currency)}
+}
+
+// Builds money from a whole currency value, like the ones exchange rates produce
+func NewMoneyFromFloat(value float64, currency string) Money {
+	return NewMoney(int64(math.Round(value*100)), currency)
+}
+
+func (m Money) Float() float64 {
+	return float64(m.Hundredths) / 100
+}
+
+func (m Money) IsZero() bool {
+	return m.Hundredths == 0
+}
+
+// Renders in the currency's Steam format. Unknown currencies are shown with their code
+func (m Money) Format() string {
+	return m.FormatLocale("")
+}
+
+// Same as Format, but numbers are grouped the way readers of the language expect, "pt-br" reads as "pt".
+// Empty and unknown languages keep the currency's Steam format
+func (m Money) FormatLocale(languageCode string) string {
+	format, isExists := currencyFormats[m.Currency]
+	if !isExists {
+		format = currencyFormat{symbol: " " + m.Currency, isSuffix: true, decimals: 2, decimalSeparator: ".", thousandsSeparator: ","}
+	}
+
+	baseLanguage, _, _ := strings.Cut(strings.ToLower(languageCode), "-")
+	if separators, isExists := languageSeparators[baseLanguage]; isExists {
+		format.decimalSeparator = separators.decimal
+		format.thousandsSeparator = separators.thousands
+	}
+
+	amount := m.Hundredths
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	var number string
+	if format.decimals == 0 {
+		number = groupThousands((amount+50)/100, format.thousandsSeparator)
+	} else {
+		number = groupThousands(amount/100, format.thousandsSeparator) + format.decimalSeparator + fmt.Sprintf("%02d", amount%100)
+	}
+
+	if format.isSuffix {
+		return sign + number + format.symbol
+	}
+
+	return sign + format.symbol + number
+}
+
+func groupThousands(value int64, separator string) string {
+	digits := strconv.FormatInt(value, 10)
+	if len(digits) <= 3 {
+		return digits
+	}
+
+	var builder strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			builder.WriteString(separator)
+		}
+		builder.WriteRune(digit)
+	}
+
+	return builder.String()
+}
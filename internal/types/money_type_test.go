@@ -0,0 +1,53 @@
+package types
+
+import "testing"
+
+func TestMoneyFormat(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		want  string
+	}{
+		{name: "dollars", money: NewMoney(1499, "usd"), want: "$14.99"},
+		{name: "thousands", money: NewMoney(123456, "USD"), want: "$1,234.56"},
+		{name: "euro suffix", money: NewMoney(123456, "EUR"), want: "1 234,56€"},
+		{name: "whole rubles are rounded", money: NewMoney(12350, "RUB"), want: "124 ₽"},
+		{name: "yen without decimals", money: NewMoney(150000, "JPY"), want: "¥ 1,500"},
+		{name: "swiss grouping", money: NewMoney(123456, "CHF"), want: "CHF 1'234.56"},
+		{name: "zero", money: NewMoney(0, "GBP"), want: "£0.00"},
+		{name: "negative", money: NewMoney(-250, "USD"), want: "-$2.50"},
+		{name: "unknown currency keeps its code", money: NewMoney(1000, "XYZ"), want: "10.00 XYZ"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.money.Format(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMoneyFormatLocale(t *testing.T) {
+	tests := []struct {
+		name     string
+		money    Money
+		language string
+		want     string
+	}{
+		{name: "empty language keeps steam format", money: NewMoney(123456, "EUR"), language: "", want: "1 234,56€"},
+		{name: "english reader of euro", money: NewMoney(123456, "EUR"), language: "en", want: "1,234.56€"},
+		{name: "german reader of dollars", money: NewMoney(123456, "USD"), language: "de", want: "$1.234,56"},
+		{name: "regional code reads as base language", money: NewMoney(123456, "USD"), language: "pt-BR", want: "$1.234,56"},
+		{name: "unknown language keeps steam format", money: NewMoney(123456, "USD"), language: "xx", want: "$1,234.56"},
+		{name: "decimals stay the currency's", money: NewMoney(150000, "JPY"), language: "ru", want: "¥ 1 500"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.money.FormatLocale(test.language); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
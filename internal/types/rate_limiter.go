@@ -0,0 +1,78 @@
+package types
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Spaces out calls so that no more than one happens per interval
+type RateLimiter struct {
+	mutex    sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func NewRateLimiter(requestsPerSecond float64) *RateLimiter {
+	return &RateLimiter{
+		interval: time.Duration(float64(time.Second) / requestsPerSecond),
+	}
+}
+
+// Blocks until the caller is allowed to make a request or the context is done.
+// Slots are only taken when granted, so waits cut by a deadline leave nothing reserved
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		l.mutex.Lock()
+		now := time.Now()
+		if !l.next.After(now) {
+			l.next = now.Add(l.interval)
+			l.mutex.Unlock()
+			return nil
+		}
+		wait := l.next.Sub(now)
+		l.mutex.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// Hands out one limiter per host, so every client and worker calling a host shares its budget
+type HostRateLimiters struct {
+	mutex    sync.Mutex
+	limiters map[string]*RateLimiter
+}
+
+func NewHostRateLimiters() *HostRateLimiters {
+	return &HostRateLimiters{limiters: make(map[string]*RateLimiter)}
+}
+
+// Rate is fixed by whoever asks for the host first, later callers get the same limiter
+func (l *HostRateLimiters) Get(baseUrl string, requestsPerSecond float64) *RateLimiter {
+	host := baseUrl
+	if parsedUrl, err := url.Parse(baseUrl); err == nil && parsedUrl.Host != "" {
+		host = parsedUrl.Host
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	limiter, isExists := l.limiters[host]
+	if !isExists {
+		limiter = NewRateLimiter(requestsPerSecond)
+		l.limiters[host] = limiter
+	}
+
+	return limiter
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"sync"
+	"unicode/utf8"
+)
+
+// Long samples are cut, escaped for html they still have to fit into a telegram message
+const maxDriftSampleLength = 700
+
+type UpstreamDrift struct {
+	Upstream string
+	Problem  string
+	Sample   string
+}
+
+type UpstreamStats struct {
+	Attempts  int
+	Successes int
+}
+
+func (s UpstreamStats) GetSuccessRate() float64 {
+	if s.Attempts == 0 {
+		return 1
+	}
+
+	return float64(s.Successes) / float64(s.Attempts)
+}
+
+// Collects signs of upstreams changing their responses between flushes.
+// Nil monitor ignores everything, so clients work without one
+type UpstreamMonitor struct {
+	mutex      sync.Mutex
+	drifts     []UpstreamDrift
+	seenDrifts map[string]bool
+	stats      map[string]UpstreamStats
+}
+
+func NewUpstreamMonitor() *UpstreamMonitor {
+	return &UpstreamMonitor{
+		seenDrifts: make(map[string]bool),
+		stats:      make(map[string]UpstreamStats),
+	}
+}
+
+// Only the first sample of each problem is kept
+func (m *UpstreamMonitor) ReportDrift(upstream string, problem string, sample string) {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	key := upstream + ": " + problem
+	if m.seenDrifts[key] {
+		return
+	}
+	m.seenDrifts[key] = true
+
+	if len(sample) > maxDriftSampleLength {
+		// Never split a multibyte character
+		cut := maxDriftSampleLength
+		for cut > 0 && !utf8.RuneStart(sample[cut]) {
+			cut--
+		}
+		sample = sample[:cut] + "..."
+	}
+
+	m.drifts = append(m.drifts, UpstreamDrift{Upstream: upstream, Problem: problem, Sample: sample})
+}
+
+func (m *UpstreamMonitor) RecordResult(upstream string, isSuccess bool) {
+	if m == nil {
+		return
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stats := m.stats[upstream]
+	stats.Attempts++
+	if isSuccess {
+		stats.Successes++
+	}
+	m.stats[upstream] = stats
+}
+
+// Returns everything collected since the previous flush and starts over
+func (m *UpstreamMonitor) Flush() ([]UpstreamDrift, map[string]UpstreamStats) {
+	if m == nil {
+		return nil, nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	drifts, stats := m.drifts, m.stats
+	m.drifts = nil
+	m.seenDrifts = make(map[string]bool)
+	m.stats = make(map[string]UpstreamStats)
+
+	return drifts, stats
+}